@@ -2,76 +2,323 @@ package constant
 
 import (
 	"bytes"
-	"os"
+	"errors"
+	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 	"text/template"
+	"time"
 )
 
+// The maximum number of nodes that can be chained together through const references while evaluating a node.
+const MaxDepth = 64
+
+// A CycleError is returned when a node's template references itself through a chain of other nodes.
+type CycleError struct {
+	// Full names of the nodes in the cycle, starting and ending with the same node.
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return "Cyclic reference: " + strings.Join(e.Path, " -> ")
+}
+
+// A DepthError is returned when a chain of const references is longer than MaxDepth.
+type DepthError struct {
+	// Full names of the nodes in the chain, starting with the node being evaluated.
+	Path []string
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("Reference chain exceeds maximum depth of %d: %s", MaxDepth, strings.Join(e.Path, " -> "))
+}
+
+// A TemplateError is returned when a node's template can't be parsed or executed.
+type TemplateError struct {
+	// Full name of the node whose template failed.
+	Name string
+	// Either "parse" or "execute".
+	Op string
+	// Position in the template where the error occurred.
+	// Line and Col are 0 if the position is unknown.
+	Line int
+	Col  int
+	// The error returned by text/template.
+	// If the node is secret (see Secret) the message of the error is Redacted, as it can contain part of the node's value.
+	Err error
+}
+
+var templateErrorPrefix = regexp.MustCompile(`^template: constant:(\d+)(?::(\d+))?: `)
+
+func newTemplateError(name, op string, err error, secret bool) *TemplateError {
+	e := &TemplateError{Name: name, Op: op, Err: err}
+	if match := templateErrorPrefix.FindStringSubmatch(err.Error()); match != nil {
+		e.Line, _ = strconv.Atoi(match[1])
+		e.Col, _ = strconv.Atoi(match[2])
+	}
+	if secret {
+		e.Err = &redactedError{err}
+	}
+	return e
+}
+
+// Wraps an error whose message can contain a secret value, replacing the message with Redacted.
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return Redacted
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func (e *TemplateError) Error() string {
+	pos := ""
+	if e.Line > 0 {
+		pos = ":" + strconv.Itoa(e.Line)
+		if e.Col > 0 {
+			pos += ":" + strconv.Itoa(e.Col)
+		}
+	}
+	msg := templateErrorPrefix.ReplaceAllString(e.Err.Error(), "")
+	return fmt.Sprintf("Unable to %s template of %s%s: %s", e.Op, e.Name, pos, msg)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
 // Returns the value of the node as defined by path.
 // If the node's default value is nil an empty string is returned.
-// If the envionment variable associated with the node is not equal to an empty string that value is used instead of the node's default value.
+// If one of the tree's sources (by default the environment variable associated with the node) has a value for the node that is not equal to an empty string that value is used instead of the node's default value.
+// If the tree was created with WithEmptyValues an empty value from a source is also used.
 // Templates in the node's value are parsed (see sections Template, Template Context and Example for details).
+// If the node's value can't be evaluated an empty string is returned (see Eval for the reason).
+// Unlike String, Str returns the real value of secret nodes (see Secret).
 func (n *Node) Str(path ...string) string {
 	node := n.Node(path...)
 	if node == nil {
 		return ""
 	}
 
-	node_fullname := node.FullName()
-	tmpl := node.Default()
+	val, _ := node.eval(nil, nil, nil)
+	return val
+}
 
-	node.mutex.RLock()
-	defer node.mutex.RUnlock()
+// Returns the value of the node as defined by path in the same way as n.Str(path...).
+//
+// Unlike Str, Eval reports why a value could not be evaluated.
+// If the node doesn't exist ErrNotExist is returned.
+// If the node's template can't be parsed or executed a *TemplateError is returned.
+// If the node references itself through other nodes a *CycleError or *DepthError is returned.
+// If one of the tree's sources fails a *SourceError is returned.
+// If the value is rejected by one of the node's validators a *ValidationError is returned.
+func (n *Node) Eval(path ...string) (string, error) {
+	node := n.Node(path...)
+	if node == nil {
+		return "", ErrNotExist
+	}
+
+	return node.eval(nil, nil, nil)
+}
+
+// Evaluates the node's value.
+// chain is the list of nodes already being evaluated which led to this node being evaluated.
+// sources are the tree's sources to look up values from (see treeConfig.snapshot), or nil to use the tree's current sources.
+// Nodes referenced by the node's template are looked up from the same sources.
+// If trace is not nil it is filled in with where the value came from (see Explain).
+func (n *Node) eval(chain []*Node, sources []Source, trace *Explanation) (val string, err error) {
+	if sources == nil {
+		sources = n.config.snapshot()
+	}
+	if trace != nil {
+		trace.Name = n.FullName()
+		defer func() {
+			trace.Secret = n.IsSecret()
+			// An empty secret, such as an unset password in a subtree rendered by json, doesn't make the value secret
+			for _, reference := range trace.References {
+				trace.Secret = trace.Secret || reference.Secret && reference.Value != ""
+			}
+			trace.Value, trace.Err = val, err
+			if trace.Secret && val != "" {
+				trace.Value = Redacted
+			}
+		}()
+	}
+
+	for i, prev := range chain {
+		if prev == n {
+			return "", &CycleError{fullNames(append(chain[i:len(chain):len(chain)], n))}
+		}
+	}
+
+	chain = append(chain[:len(chain):len(chain)], n)
+	if len(chain) > MaxDepth {
+		return "", &DepthError{fullNames(chain)}
+	}
+
+	node_fullname := n.FullName()
+	tmpl, has_default := n.defaultValue()
 
-	parent := node.parent
+	result, ok, err := n.lookup(node_fullname, sources)
+	if err != nil {
+		return "", err
+	} else if ok {
+		tmpl = result.val
+	}
 
-	if env := os.Getenv(node_fullname); env != "" {
-		tmpl = env
+	if trace != nil {
+		trace.Layer, trace.Source, trace.File = "default", nil, ""
+		if ok {
+			trace.Layer, trace.Source, trace.File = describeSource(result.source), result.source, result.file
+			if f, is_file := result.source.(*FileSource); is_file && result.file == "" {
+				trace.File = f.Filename()
+			}
+		} else if !has_default {
+			trace.Layer = "none"
+		}
+		trace.Raw = n.redact(tmpl)
 	}
 
-	t, err := template.New("constant").Funcs(template.FuncMap{
-		"const": func(path ...string) string {
-			if len(path) == 1 && path[0] == node.name {
-				return ""
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	parent := n.parent
+
+	t, err := n.parsed(tmpl)
+	if err != nil {
+		return "", newTemplateError(node_fullname, "parse", err, n.secret)
+	}
+
+	t.Funcs(template.FuncMap{
+		"const": func(path ...string) (string, error) {
+			if parent == nil || len(path) == 1 && path[0] == n.name {
+				return "", nil
+			}
+			node := parent.Node(path...)
+			if node == nil {
+				return "", nil
 			}
-			return parent.Str(path...)
+			if trace == nil {
+				return node.eval(chain, sources, nil)
+			}
+			reference := &Explanation{}
+			trace.References = append(trace.References, reference)
+			return node.eval(chain, sources, reference)
+		},
+		"json": func(path ...string) (string, error) {
+			if parent == nil {
+				return "null", nil
+			}
+			node := parent.Node(path...)
+			if node == nil || node == n {
+				return "null", nil
+			}
+			val, err := node.jsonValue(chain, sources, n, trace)
+			if err != nil {
+				return "", err
+			}
+			return encodeJSON(val)
 		},
 		"list": func() []string {
+			if parent == nil {
+				return []string{}
+			}
 			consts := parent.List()
 			for i, cnst := range consts {
-				if cnst == node.name {
+				if cnst == n.name {
 					consts = append(consts[:i], consts[i+1:]...)
 				}
 			}
 			return consts
 		},
 		"isset": func(path ...string) bool {
+			if parent == nil {
+				return false
+			}
 			return parent.IsSet(path...)
 		},
-	}).Parse(tmpl)
-
-	if err != nil {
-		return ""
-	}
+	})
 
 	var byte_string bytes.Buffer
 	if err = t.Execute(&byte_string, nil); err != nil {
-		return ""
+		var cycle *CycleError
+		if errors.As(err, &cycle) {
+			return "", cycle
+		}
+		var depth *DepthError
+		if errors.As(err, &depth) {
+			return "", depth
+		}
+		return "", newTemplateError(node_fullname, "execute", err, n.secret)
+	}
+
+	val = byte_string.String()
+	for _, validator := range n.validators {
+		if err := validator(val); err != nil {
+			return "", &ValidationError{node_fullname, err}
+		}
 	}
 
-	return byte_string.String()
+	return val, nil
+}
+
+// Placeholders for the functions available in a template.
+// The real functions are bound to each copy of a parsed template before it is executed.
+var templateFuncs = template.FuncMap{
+	"const": func(path ...string) (string, error) { return "", nil },
+	"json":  func(path ...string) (string, error) { return "", nil },
+	"list":  func() []string { return nil },
+	"isset": func(path ...string) bool { return false },
+}
+
+// Returns a copy of the parsed template for src.
+// The parsed template is cached on the node and only parsed again when src changes.
+func (n *Node) parsed(src string) (*template.Template, error) {
+	n.tmpl_mutex.Lock()
+	defer n.tmpl_mutex.Unlock()
+
+	if n.tmpl == nil || n.tmpl_src != src {
+		t, err := template.New("constant").Funcs(templateFuncs).Parse(src)
+		if err != nil {
+			return nil, err
+		}
+		n.tmpl = t
+		n.tmpl_src = src
+	}
+
+	return n.tmpl.Clone()
+}
+
+func fullNames(nodes []*Node) []string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.FullName()
+	}
+	return names
 }
 
 // Alias of n.Str()
+// If the node is secret (see Secret), or its template references a secret node, and its value is not empty, Redacted is returned instead.
 func (n *Node) String() string {
-	return n.Str()
+	val, _ := n.redacted()
+	return val
 }
 
 // Returns the value of n.Str(path...) as an integer.
 //
 // Follows convention of strconv.Atoi (https://golang.org/pkg/strconv/#Atoi).
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
 func (n *Node) Int(path ...string) (val int, err error) {
-	val, err = strconv.Atoi(n.Str(path...))
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = strconv.Atoi(str)
 	return
 }
 
@@ -81,11 +328,114 @@ func (n *Node) IntI(path ...string) (val int) {
 	return
 }
 
+// Returns the value of n.Str(path...) as an int64.
+//
+// Follows convention of strconv.ParseInt (https://golang.org/pkg/strconv/#ParseInt) with base 10.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Int64(path ...string) (val int64, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = strconv.ParseInt(str, 10, 64)
+	return
+}
+
+// Run n.Int64(path...) but ignore errors
+func (n *Node) Int64I(path ...string) (val int64) {
+	val, _ = n.Int64(path...)
+	return
+}
+
+// Returns the value of n.Str(path...) as an int32.
+//
+// Follows convention of strconv.ParseInt (https://golang.org/pkg/strconv/#ParseInt) with base 10.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Int32(path ...string) (val int32, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val64, err := strconv.ParseInt(str, 10, 32)
+	val = int32(val64)
+	return
+}
+
+// Run n.Int32(path...) but ignore errors
+func (n *Node) Int32I(path ...string) (val int32) {
+	val, _ = n.Int32(path...)
+	return
+}
+
+// Returns the value of n.Str(path...) as a uint.
+//
+// Follows convention of strconv.ParseUint (https://golang.org/pkg/strconv/#ParseUint) with base 10.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Uint(path ...string) (val uint, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val64, err := strconv.ParseUint(str, 10, 0)
+	val = uint(val64)
+	return
+}
+
+// Run n.Uint(path...) but ignore errors
+func (n *Node) UintI(path ...string) (val uint) {
+	val, _ = n.Uint(path...)
+	return
+}
+
+// Returns the value of n.Str(path...) as a uint64.
+//
+// Follows convention of strconv.ParseUint (https://golang.org/pkg/strconv/#ParseUint) with base 10.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Uint64(path ...string) (val uint64, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = strconv.ParseUint(str, 10, 64)
+	return
+}
+
+// Run n.Uint64(path...) but ignore errors
+func (n *Node) Uint64I(path ...string) (val uint64) {
+	val, _ = n.Uint64(path...)
+	return
+}
+
+// Returns the value of n.Str(path...) as a uint32.
+//
+// Follows convention of strconv.ParseUint (https://golang.org/pkg/strconv/#ParseUint) with base 10.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Uint32(path ...string) (val uint32, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val64, err := strconv.ParseUint(str, 10, 32)
+	val = uint32(val64)
+	return
+}
+
+// Run n.Uint32(path...) but ignore errors
+func (n *Node) Uint32I(path ...string) (val uint32) {
+	val, _ = n.Uint32(path...)
+	return
+}
+
 // Returns the value of n.Str(path...) as a float64.
 //
 // Follows convention of strconv.ParseFloat (https://golang.org/pkg/strconv/#ParseFloat).
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
 func (n *Node) Float(bitSize int, path ...string) (val float64, err error) {
-	val, err = strconv.ParseFloat(n.Str(path...), bitSize)
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = strconv.ParseFloat(str, bitSize)
 	return
 }
 
@@ -95,11 +445,36 @@ func (n *Node) FloatI(bitSize int, path ...string) (val float64) {
 	return
 }
 
+// Returns the value of n.Str(path...) as a float32.
+//
+// Follows convention of strconv.ParseFloat (https://golang.org/pkg/strconv/#ParseFloat) with a bitSize of 32.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Float32(path ...string) (val float32, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val64, err := strconv.ParseFloat(str, 32)
+	val = float32(val64)
+	return
+}
+
+// Run n.Float32(path...) but ignore errors
+func (n *Node) Float32I(path ...string) (val float32) {
+	val, _ = n.Float32(path...)
+	return
+}
+
 // Returns the value of n.Str(path...) as a boolean.
 //
 // Follows convention of strconv.ParseBool (https://golang.org/pkg/strconv/#ParseBool).
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
 func (n *Node) Bool(path ...string) (val bool, err error) {
-	val, err = strconv.ParseBool(n.Str(path...))
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = strconv.ParseBool(str)
 	return
 }
 
@@ -109,6 +484,83 @@ func (n *Node) BoolI(path ...string) (val bool) {
 	return
 }
 
+// Returns the value of the node as defined by path in the same way as n.Str(path...), and whether the node has a value.
+//
+// ok is false if the node doesn't exist, if none of the tree's sources has a value for the node and its default value is nil, or if the node's value can't be evaluated (see Eval).
+// Unlike IsSet, Lookup takes the tree's sources into account, so with WithEmptyValues an empty environment variable can be told apart from an unset one.
+func (n *Node) Lookup(path ...string) (val string, ok bool) {
+	node := n.Node(path...)
+	if node == nil {
+		return "", false
+	}
+
+	trace := &Explanation{}
+	val, err := node.eval(nil, nil, trace)
+	if err != nil {
+		return "", false
+	}
+	return val, trace.Layer != "none"
+}
+
+// Returns the value of n.Str(path...) as a time.Duration.
+//
+// Follows convention of time.ParseDuration (https://golang.org/pkg/time/#ParseDuration).
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Duration(path ...string) (val time.Duration, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = time.ParseDuration(str)
+	return
+}
+
+// Run n.Duration(path...) but ignore errors
+func (n *Node) DurationI(path ...string) (val time.Duration) {
+	val, _ = n.Duration(path...)
+	return
+}
+
+// Returns the value of n.Str(path...) as a time.Time.
+//
+// Follows convention of time.Parse (https://golang.org/pkg/time/#Parse).
+// Time defaults set with New are stored in the time.RFC3339Nano layout, which time.RFC3339 also parses.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Time(layout string, path ...string) (val time.Time, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = time.Parse(layout, str)
+	return
+}
+
+// Run n.Time(layout, path...) but ignore errors
+func (n *Node) TimeI(layout string, path ...string) (val time.Time) {
+	val, _ = n.Time(layout, path...)
+	return
+}
+
+// Returns the value of n.Str(path...) as a *time.Location.
+//
+// Follows convention of time.LoadLocation (https://golang.org/pkg/time/#LoadLocation), so an empty value is UTC.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) Location(path ...string) (val *time.Location, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = time.LoadLocation(str)
+	return
+}
+
+// Run n.Location(path...) but ignore errors
+// Returns nil if the value is not a known location.
+func (n *Node) LocationI(path ...string) (val *time.Location) {
+	val, _ = n.Location(path...)
+	return
+}
+
 // Returns false if: the node as defined by path doesn't exist; the node's default value is nil; the node's default value it an empty string.
 // Otherwise returns true.
 func (n *Node) IsSet(path ...string) bool {
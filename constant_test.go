@@ -1,11 +1,14 @@
 package constant_test
 
 import (
+	"errors"
 	"github.com/JamesStewy/constant"
 	"os"
 	"reflect"
 	"sort"
+	"strconv"
 	"testing"
+	"time"
 )
 
 var tree *constant.Node
@@ -42,6 +45,19 @@ func (s stringer) String() string {
 
 type invalid string
 
+type port uint16
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte([]string{"debug", "info"}[l]), nil
+}
+
+var pointer_value = 42
+var duration_value = 90 * time.Second
+var time_value = time.Date(2016, 1, 2, 15, 4, 5, 500000000, time.UTC)
+var nil_pointer *int
+
 var tests = []test_type{
 	{constPair{[]string{"string1"}, "string value"}, "string value", false, "string1", "string value", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, true},
 	{constPair{[]string{"string2"}, ""}, "", false, "string2", "", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, false},
@@ -59,6 +75,16 @@ var tests = []test_type{
 	{constPair{[]string{"bool6"}, "f"}, "f", false, "bool6", "f", val_err{0, true}, val_err{0.0, true}, val_err{false, false}, true},
 	{constPair{[]string{"bool7"}, "T"}, "T", false, "bool7", "T", val_err{0, true}, val_err{0.0, true}, val_err{true, false}, true},
 	{constPair{[]string{"bool8"}, "F"}, "F", false, "bool8", "F", val_err{0, true}, val_err{0.0, true}, val_err{false, false}, true},
+	{constPair{[]string{"duration1"}, 90 * time.Second}, "1m30s", false, "duration1", "1m30s", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, true},
+	{constPair{[]string{"time1"}, time.Date(2016, 1, 2, 15, 4, 5, 500000000, time.UTC)}, "2016-01-02T15:04:05.5Z", false, "time1", "2016-01-02T15:04:05.5Z", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, true},
+	{constPair{[]string{"int64_1"}, int64(-1 << 40)}, "-1099511627776", false, "int64_1", "-1099511627776", val_err{-1 << 40, false}, val_err{-1099511627776.0, false}, val_err{false, true}, true},
+	{constPair{[]string{"uint1"}, port(8080)}, "8080", false, "uint1", "8080", val_err{8080, false}, val_err{8080.0, false}, val_err{false, true}, true},
+	{constPair{[]string{"float32_1"}, float32(0.1)}, "0.1", false, "float32_1", "0.1", val_err{0, true}, val_err{0.1, false}, val_err{false, true}, true},
+	{constPair{[]string{"complex1"}, complex(1, -2.5)}, "(1-2.5i)", false, "complex1", "(1-2.5i)", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, true},
+	{constPair{[]string{"textMarshaler1"}, level(1)}, "info", false, "textMarshaler1", "info", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, true},
+	{constPair{[]string{"pointer1"}, &pointer_value}, "42", false, "pointer1", "42", val_err{42, false}, val_err{42.0, false}, val_err{false, true}, true},
+	{constPair{[]string{"durationPointer1"}, &duration_value}, "1m30s", false, "durationPointer1", "1m30s", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, true},
+	{constPair{[]string{"timePointer1"}, &time_value}, "2016-01-02T15:04:05.5Z", false, "timePointer1", "2016-01-02T15:04:05.5Z", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, true},
 	{constPair{[]string{"invalid1"}, invalid("not a valid type")}, "", true, "", "", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, false},
 	{constPair{[]string{"nil_value"}, nil}, "", false, "nil_value", "", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, false},
 	{constPair{[]string{"2name"}, "name starting with number"}, "", true, "", "", val_err{0, true}, val_err{0.0, true}, val_err{false, true}, false},
@@ -253,6 +279,57 @@ func TestFloat(t *testing.T) {
 	}
 }
 
+func TestSizedNumbers(t *testing.T) {
+	number_tree := constant.NewTree("number", "_")
+	number_tree.New("INT64", int64(-1<<62))
+	number_tree.New("INT32", int32(-1<<31))
+	number_tree.New("UINT", uint(1<<31))
+	number_tree.New("UINT64", uint64(1<<63))
+	number_tree.New("UINT32", uint32(1<<32-1))
+	number_tree.New("FLOAT32", float32(3.4e38))
+	number_tree.New("NEGATIVE", -1)
+	number_tree.New("NIL", (*int)(nil))
+	if _, err := number_tree.New("NIL_POINTER", &nil_pointer); err != nil {
+		t.Error("For", "NIL_POINTER", "expected no error", "got", err)
+	}
+
+	if val, err := number_tree.Int64("INT64"); val != -1<<62 || err != nil {
+		t.Error("For", "INT64", "expected", int64(-1<<62), "got (", val, err, ")")
+	}
+	if val, err := number_tree.Int32("INT32"); val != -1<<31 || err != nil {
+		t.Error("For", "INT32", "expected", int32(-1<<31), "got (", val, err, ")")
+	}
+	if val, err := number_tree.Int32("INT64"); err == nil {
+		t.Error("For", "INT64 as int32", "expected error", "got", val)
+	}
+	if val, err := number_tree.Uint("UINT"); val != 1<<31 || err != nil {
+		t.Error("For", "UINT", "expected", uint(1<<31), "got (", val, err, ")")
+	}
+	if val, err := number_tree.Uint64("UINT64"); val != 1<<63 || err != nil {
+		t.Error("For", "UINT64", "expected", uint64(1<<63), "got (", val, err, ")")
+	}
+	if val, err := number_tree.Uint32("UINT32"); val != 1<<32-1 || err != nil {
+		t.Error("For", "UINT32", "expected", uint32(1<<32-1), "got (", val, err, ")")
+	}
+	if val := number_tree.Uint32I("UINT64"); val != 1<<32-1 {
+		t.Error("For", "UINT64 as uint32", "expected", uint32(1<<32-1), "got", val)
+	}
+	if val, err := number_tree.Float32("FLOAT32"); val != 3.4e38 || err != nil {
+		t.Error("For", "FLOAT32", "expected", float32(3.4e38), "got (", val, err, ")")
+	}
+	if val, err := number_tree.Uint("NEGATIVE"); err == nil {
+		t.Error("For", "NEGATIVE", "expected error", "got", val)
+	}
+	if val := number_tree.Int64I("NEGATIVE") + int64(number_tree.UintI("UINT")); val != 1<<31-1 {
+		t.Error("For", "NEGATIVE + UINT", "expected", 1<<31-1, "got", val)
+	}
+	for _, name := range []string{"NIL", "NIL_POINTER"} {
+		if _, ok := number_tree.Lookup(name); ok {
+			t.Error("For", name, "expected no default value", "got", number_tree.Default(name))
+		}
+	}
+}
+
 func TestBool(t *testing.T) {
 	for _, test := range tests {
 		val, err := tree.Bool(test.pair.path...)
@@ -266,6 +343,63 @@ func TestBool(t *testing.T) {
 	}
 }
 
+func TestDuration(t *testing.T) {
+	time_tree := constant.NewTree("time", "_")
+	time_tree.New("TIMEOUT", 90*time.Second)
+	time_tree.New("RETRY", "250ms")
+	time_tree.New("INVALID", "soon")
+
+	results := map[string]val_err{
+		"TIMEOUT": {90 * time.Second, false},
+		"RETRY":   {250 * time.Millisecond, false},
+		"INVALID": {time.Duration(0), true},
+		"MISSING": {time.Duration(0), true},
+	}
+
+	for name, exp := range results {
+		val, err := time_tree.Duration(name)
+		if val != exp.val || (err != nil) != exp.err {
+			t.Error(
+				"For", name,
+				"expected", exp,
+				"got (", val, err, ")",
+			)
+		}
+		if val := time_tree.DurationI(name); val != exp.val {
+			t.Error("For", name, "expected", exp.val, "got", val)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	started := time.Date(2016, 1, 2, 15, 4, 5, 500000000, time.UTC)
+	time_tree := constant.NewTree("time", "_")
+	time_tree.New("STARTED", started)
+	time_tree.New("DATE", "2016-01-02")
+	time_tree.New("ZONE", "Australia/Sydney")
+	time_tree.New("INVALID", "Nowhere/Special")
+
+	if val, err := time_tree.Time(time.RFC3339, "STARTED"); !val.Equal(started) || err != nil {
+		t.Error("For", "STARTED", "expected", started, "got (", val, err, ")")
+	}
+	if val := time_tree.TimeI("2006-01-02", "DATE"); !val.Equal(time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("For", "DATE", "expected", "2016-01-02", "got", val)
+	}
+	if val, err := time_tree.Time("2006-01-02", "STARTED"); err == nil {
+		t.Error("For", "STARTED with layout 2006-01-02", "expected error", "got", val)
+	}
+
+	if val, err := time_tree.Location("ZONE"); err != nil || val.String() != "Australia/Sydney" {
+		t.Error("For", "ZONE", "expected", "Australia/Sydney", "got (", val, err, ")")
+	}
+	if val, err := time_tree.Location("INVALID"); err == nil {
+		t.Error("For", "INVALID", "expected error", "got", val)
+	}
+	if val := time_tree.LocationI("MISSING"); val != nil {
+		t.Error("For", "MISSING", "expected", nil, "got", val)
+	}
+}
+
 func TestIsSet(t *testing.T) {
 	for _, test := range tests {
 		val := tree.IsSet(test.pair.path...)
@@ -358,3 +492,176 @@ func TestDelete(t *testing.T) {
 		)
 	}
 }
+
+func TestCycle(t *testing.T) {
+	cycle_tree := constant.NewTree("cycle", "_")
+	cycle_tree.New("A", `{{ const "B" }}`)
+	cycle_tree.New("B", `{{ const "C" }}`)
+	cycle_tree.New("C", `{{ const "A" }}`)
+	cycle_tree.New("D", `value of D`)
+	cycle_tree.New("E", `{{ const "D" }} and {{ const "A" }}`)
+
+	for _, name := range []string{"A", "B", "C", "E"} {
+		if str := cycle_tree.Str(name); str != "" {
+			t.Error(
+				"For", name,
+				"expected", "",
+				"got", str,
+			)
+		}
+	}
+
+	_, err := cycle_tree.Eval("E")
+	var cycle *constant.CycleError
+	if !errors.As(err, &cycle) || !reflect.DeepEqual(cycle.Path, []string{"cycle_A", "cycle_B", "cycle_C", "cycle_A"}) {
+		t.Error(
+			"For", "E",
+			"expected cycle", []string{"cycle_A", "cycle_B", "cycle_C", "cycle_A"},
+			"got", err,
+		)
+	}
+
+	if str := cycle_tree.Str("D"); str != "value of D" {
+		t.Error(
+			"For", "D",
+			"expected", "value of D",
+			"got", str,
+		)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	depth_tree := constant.NewTree("depth", "_")
+	node := depth_tree
+	for i := 0; i <= constant.MaxDepth; i++ {
+		node.New("V", `{{ const "N" "V" }}x`)
+		node, _ = node.New("N", nil)
+	}
+	node.New("V", "end")
+
+	var depth *constant.DepthError
+	if _, err := depth_tree.Eval("V"); !errors.As(err, &depth) {
+		t.Error(
+			"For chain longer than", constant.MaxDepth,
+			"expected DepthError",
+			"got", err,
+		)
+	}
+
+	if str := depth_tree.Str("V"); str != "" {
+		t.Error(
+			"For chain longer than", constant.MaxDepth,
+			"expected", "",
+			"got", str,
+		)
+	}
+
+	if str := depth_tree.Node("N", "N").Str("V"); str == "" {
+		t.Error(
+			"For chain shorter than", constant.MaxDepth,
+			"expected non empty string",
+			"got", str,
+		)
+	}
+}
+
+func TestEval(t *testing.T) {
+	eval_tree := constant.NewTree("eval", "_")
+	eval_tree.New("parse", "first line\n{{ const \"x\"")
+	eval_tree.New("execute", `{{ index "abc" 10 }}`)
+	eval_tree.New("nested", `{{ const "parse" }}`)
+
+	errs := []struct {
+		path []string
+		op   string
+		line int
+	}{
+		{[]string{"parse"}, "parse", 2},
+		{[]string{"execute"}, "execute", 1},
+		{[]string{"nested"}, "execute", 1},
+	}
+
+	for _, test := range errs {
+		_, err := eval_tree.Eval(test.path...)
+		var tmpl_err *constant.TemplateError
+		if !errors.As(err, &tmpl_err) || tmpl_err.Op != test.op || tmpl_err.Line != test.line || tmpl_err.Name != "eval_"+test.path[0] {
+			t.Error(
+				"For", test.path,
+				"expected", test.op, "error on line", test.line,
+				"got", err,
+			)
+		}
+	}
+
+	if _, err := eval_tree.Eval("doesntexist"); err != constant.ErrNotExist {
+		t.Error(
+			"For", "doesntexist",
+			"expected", constant.ErrNotExist,
+			"got", err,
+		)
+	}
+
+	var tmpl_err *constant.TemplateError
+	if _, err := eval_tree.Int("parse"); !errors.As(err, &tmpl_err) {
+		t.Error(
+			"For Int", "parse",
+			"expected TemplateError",
+			"got", err,
+		)
+	}
+}
+
+func chainTree(length int) *constant.Node {
+	chain_tree := constant.NewTree("bench", "_")
+	chain_tree.New("V0", "value")
+	for i := 1; i < length; i++ {
+		chain_tree.New("V"+strconv.Itoa(i), `{{ const "V`+strconv.Itoa(i-1)+`" }}`)
+	}
+	return chain_tree
+}
+
+func BenchmarkStrChain(b *testing.B) {
+	chain_tree := chainTree(32)
+	for i := 0; i < b.N; i++ {
+		chain_tree.Str("V31")
+	}
+}
+
+// Evaluates a new tree every iteration so that every template in the chain has to be parsed.
+// Creating the tree isn't timed, so the difference from BenchmarkStrChain is the time the cache saves.
+func BenchmarkStrChainUncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		chain_tree := chainTree(32)
+		b.StartTimer()
+		chain_tree.Str("V31")
+	}
+}
+
+func TestTemplateCache(t *testing.T) {
+	cache_tree := constant.NewTree("cache", "_")
+	cache_tree.New("host", "localhost")
+	cache_tree.New("address", `{{ const "host" }}:80`)
+
+	results := []struct {
+		env string
+		str string
+	}{
+		{"", "localhost:80"},
+		{`{{ const "host" }}:8080`, "localhost:8080"},
+		{`{{ const "host" }}:8080`, "localhost:8080"},
+		{"", "localhost:80"},
+	}
+
+	for _, test := range results {
+		os.Setenv("cache_address", test.env)
+		if str := cache_tree.Str("address"); str != test.str {
+			t.Error(
+				"For env", test.env,
+				"expected", test.str,
+				"got", str,
+			)
+		}
+	}
+	os.Unsetenv("cache_address")
+}
@@ -0,0 +1,134 @@
+package constant
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A Constraint checks the values of several nodes against each other.
+// Constraints are added to a node with Constrain and are checked by Validate.
+type Constraint func(s *Scope) error
+
+// A ConstraintError is returned by Validate when a constraint fails.
+type ConstraintError struct {
+	// Full names of the nodes read by the constraint.
+	Names []string
+	// The error returned by the constraint.
+	Err error
+}
+
+func (e *ConstraintError) Error() string {
+	return "Constraint on " + strings.Join(e.Names, ", ") + " failed: " + e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// A Scope gives a constraint access to the values of nodes.
+// Nodes are referenced by their path relative to the node the constraint was added to, in the same way as the const template function references nodes relative to the root of its context.
+// An empty path ("") references the node the constraint was added to.
+//
+// The scope records every node read, so a failing constraint can report every node involved.
+type Scope struct {
+	node    *Node
+	sources []Source
+	read    []*Node
+}
+
+// Returns the node as defined by path and records it as read.
+func (s *Scope) lookup(path ...string) *Node {
+	node := s.node.Node(path...)
+	if node == nil {
+		return nil
+	}
+
+	for _, read := range s.read {
+		if read == node {
+			return node
+		}
+	}
+	s.read = append(s.read, node)
+	return node
+}
+
+// Returns the value of the node as defined by path (see Node.Str).
+func (s *Scope) Str(path ...string) string {
+	if node := s.lookup(path...); node != nil {
+		val, _ := node.eval(nil, s.sources, nil)
+		return val
+	}
+	return ""
+}
+
+// Returns the value of the node as defined by path as an integer (see Node.Int).
+func (s *Scope) Int(path ...string) (int, error) {
+	return strconv.Atoi(s.Str(path...))
+}
+
+// Returns the value of the node as defined by path as a float64 (see Node.Float).
+func (s *Scope) Float(path ...string) (float64, error) {
+	return strconv.ParseFloat(s.Str(path...), 64)
+}
+
+// Returns the value of the node as defined by path as a boolean (see Node.Bool).
+func (s *Scope) Bool(path ...string) (bool, error) {
+	return strconv.ParseBool(s.Str(path...))
+}
+
+// Returns whether the effective value of the node as defined by path is not an empty string.
+func (s *Scope) IsSet(path ...string) bool {
+	return s.Str(path...) != ""
+}
+
+/*
+Adds a constraint to the node as defined by path.
+The constraint is checked by Validate, called on the node or any of its parents.
+
+For example the following constraints require POOL_MIN to be at most POOL_MAX, and TLS_CERT to be set whenever TLS_ENABLED is true.
+
+	database.Constrain(func(s *constant.Scope) error {
+		min, _ := s.Int("POOL_MIN")
+		max, _ := s.Int("POOL_MAX")
+		if min > max {
+			return errors.New("POOL_MIN must not be greater than POOL_MAX")
+		}
+		return nil
+	})
+
+	database.Constrain(func(s *constant.Scope) error {
+		if enabled, _ := s.Bool("TLS_ENABLED"); enabled && !s.IsSet("TLS_CERT") {
+			return errors.New("TLS_CERT is required when TLS_ENABLED is true")
+		}
+		return nil
+	})
+*/
+func (n *Node) Constrain(constraint Constraint, path ...string) error {
+	node := n.Node(path...)
+	if node == nil {
+		return ErrNotExist
+	}
+
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	node.constraints = append(node.constraints, constraint)
+	return nil
+}
+
+// Checks the constraints of the node, evaluating nodes with sources.
+// Returns a *ConstraintError for every constraint that fails.
+func (n *Node) checkConstraints(sources []Source) []error {
+	n.mutex.RLock()
+	constraints := n.constraints
+	n.mutex.RUnlock()
+
+	var errs []error
+	for _, constraint := range constraints {
+		scope := &Scope{node: n, sources: sources}
+		if err := constraint(scope); err != nil {
+			errs = append(errs, &ConstraintError{fullNames(scope.read), err})
+		}
+	}
+	return errs
+}
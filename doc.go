@@ -20,10 +20,12 @@ The following methods are available to use in a node.
 			If host=`localhost` and port=`3306` then the above template would return
 			`localhost:3306`.
 
-		Caution:
-			Although there is a check in place to test if a node references	itself,
-			there is no check for cyclic dependancy. If a cyclic dependancy is
-			created then the program will enter an infinite loop.
+		Cyclic references:
+			If a node references itself through a chain of other nodes (for
+			example A=`{{ const "B" }}` and B=`{{ const "A" }}`) evaluation stops
+			with a CycleError naming every node in the cycle. Chains of references
+			longer than MaxDepth stop with a DepthError. In both cases Str returns
+			an empty string.
 
 	{{ list }}
 		Returns a sorted slice of all nodes in the context except itself.
@@ -46,6 +48,22 @@ The following methods are available to use in a node.
 			Or if the same constants are set as well as port=`8080` then the above
 			template would return `http://localhost:8080/index.html`.
 
+	{{ json "path1" ["path2" ...] }}
+		Returns the value of another node in the same context as defined by
+		path1[, path2 ...] encoded as JSON. A node without child nodes is a JSON
+		string. A node with child nodes is a JSON object with a key for each
+		child node (except the node whose template is being executed) and, if
+		the node has a value, its own value under an empty key. Nodes that don't
+		exist and self references return `null`. If any node in the JSON is
+		secret, the value of the node using json is redacted like a secret
+		node (see section Secrets).
+
+		Example:
+			`{"host": {{ json "HOST" }}, "port": {{ json "PORT" }}}`
+			If host=`localhost` and port=`3306` then the above template would return
+			`{"host": "localhost", "port": "3306"}`, which can be decoded with
+			Node.JSON.
+
 Template Context
 
 The context for a node includes the context's root node and all of its children recursively.
@@ -84,6 +102,33 @@ then 'ADDRESS' could be set to and would return
 	`{{ isset "HOST" }}`             ->  `true`
 	`{{ isset "SOMETHING" }}`        ->  `false`
 
+Sources
+
+By default a node's value is read from the environment variable matching the node's full name, falling back to the node's default value.
+The places a tree reads values from are called sources (see Source) and can be changed when the tree is created with the WithSources option.
+Sources are consulted in order and the first non empty value is used.
+With the WithEmptyValues option an empty value is used too, so a variable set to an empty string overrides a non empty default value.
+
+Besides the environment, values can come from maps (MapSource), .env files (NewDotenvSource), directories of files (NewDirSource) and files named by <FullName>_FILE variables (WithFileIndirection).
+File sources can be reloaded while a program is running with a Reloader, and Watch reports when the value of a node changes.
+Values can also be overridden in memory with Set, which by default takes priority over every source (see Overrides).
+Explain reports which source or default a node's value came from, along with the nodes its template referenced.
+
+Documents
+
+Instead of calling New for every node, a tree can be created from a JSON or YAML document with LoadJSON and LoadYAML.
+Objects (mappings) become nodes with child nodes and other values become default values.
+A tree can be written back out as YAML with WriteYAML (effective values) or WriteDefaultsYAML (default values).
+
+Nodes can also be created from a struct of default values with Register, and a struct can be filled with the values of a tree with Bind.
+Both map struct fields to nodes with the `constant` struct tag.
+
+Secrets
+
+Nodes holding passwords and other secrets can be marked with the Secret option (or Conceal).
+String, and therefore fmt formatting of a node, Dump and WriteYAML replace the value of secret nodes with Redacted.
+The same applies to nodes whose templates reference a secret node, such as a connection string built from a password, so a secret can't leak through another node.
+The real value is only returned by the explicit accessors such as Str, Eval and Int.
 
 Example
 
@@ -0,0 +1,185 @@
+package constant
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*`)
+
+/*
+Parses the .env (dotenv) file read from r.
+The returned MapSource is keyed by variable name, so it can be used as a tree source keyed by full name (see WithSources).
+
+Each line has the form
+
+	[export] KEY=VALUE
+
+Blank lines and lines starting with # are ignored.
+VALUE can be:
+
+	unquoted       Leading and trailing whitespace is removed and a # at the start or preceded by whitespace starts a comment.
+	'single'       Taken literally. Can span multiple lines.
+	"double"       Can span multiple lines. Supports the escapes \n, \r, \t, \", \\ and \$, and a backslash at the end of a line joins it to the next line.
+
+A quoted value can be followed by whitespace and a comment.
+If a key appears more than once the last value is used.
+*/
+func ParseDotenv(r io.Reader) (MapSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &dotenvParser{text: strings.ReplaceAll(string(data), "\r\n", "\n")}
+	return parser.parse()
+}
+
+// Parses the named .env file.
+// See ParseDotenv for details.
+func ReadDotenv(filename string) (MapSource, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseDotenv(file)
+}
+
+type dotenvParser struct {
+	text string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("dotenv: line %d: %s", p.line+1, fmt.Sprintf(format, args...))
+}
+
+// Returns the remainder of the current line.
+func (p *dotenvParser) rest() string {
+	if i := strings.IndexByte(p.text[p.pos:], '\n'); i >= 0 {
+		return p.text[p.pos : p.pos+i]
+	}
+	return p.text[p.pos:]
+}
+
+// Moves to the start of the next line.
+func (p *dotenvParser) nextLine() {
+	p.pos += len(p.rest())
+	if p.pos < len(p.text) {
+		p.pos++
+		p.line++
+	}
+}
+
+func (p *dotenvParser) skipSpace() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) parse() (MapSource, error) {
+	values := make(MapSource)
+	for p.pos < len(p.text) {
+		p.skipSpace()
+		line := p.rest()
+		if line == "" || line[0] == '#' {
+			p.nextLine()
+			continue
+		}
+
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			p.pos += len("export")
+			p.skipSpace()
+		}
+
+		key := dotenvKey.FindString(p.rest())
+		if key == "" {
+			return nil, p.errorf("Invalid key")
+		}
+		p.pos += len(key)
+		p.skipSpace()
+		if !strings.HasPrefix(p.rest(), "=") {
+			return nil, p.errorf("Expected = after %s", key)
+		}
+		p.pos++
+		p.skipSpace()
+
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values[key] = val
+		p.nextLine()
+	}
+	return values, nil
+}
+
+func (p *dotenvParser) value() (string, error) {
+	line := p.rest()
+	if line == "" || line[0] != '\'' && line[0] != '"' {
+		// Whitespace before the value has already been skipped, so a value starting with # is a comment
+		if strings.HasPrefix(line, "#") {
+			line = ""
+		} else if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		} else if i := strings.Index(line, "\t#"); i >= 0 {
+			line = line[:i]
+		}
+		p.pos += len(p.rest())
+		return strings.TrimSpace(line), nil
+	}
+
+	quote := line[0]
+	start_line := p.line
+	p.pos++
+
+	var val strings.Builder
+	for {
+		if p.pos >= len(p.text) {
+			p.line = start_line
+			return "", p.errorf("Unterminated quoted value")
+		}
+
+		c := p.text[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			p.skipSpace()
+			if rest := p.rest(); rest != "" && rest[0] != '#' {
+				return "", p.errorf("Unexpected text after quoted value")
+			}
+			p.pos += len(p.rest())
+			return val.String(), nil
+		case c == '\n':
+			p.line++
+			val.WriteByte(c)
+		case c == '\\' && quote == '"' && p.pos < len(p.text):
+			escape := p.text[p.pos]
+			p.pos++
+			switch escape {
+			case 'n':
+				val.WriteByte('\n')
+			case 'r':
+				val.WriteByte('\r')
+			case 't':
+				val.WriteByte('\t')
+			case '"', '\\', '$':
+				val.WriteByte(escape)
+			case '\n':
+				// A line continuation, as in a shell
+				p.line++
+			default:
+				val.WriteByte('\\')
+				val.WriteByte(escape)
+			}
+		default:
+			val.WriteByte(c)
+		}
+	}
+}
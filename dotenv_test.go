@@ -0,0 +1,95 @@
+package constant_test
+
+import (
+	"github.com/JamesStewy/constant"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	values, err := constant.ParseDotenv(strings.NewReader(`# Local development
+MYAPP_RUNTIME=dev
+export MYAPP_DATABASE_HOST = db.local # comment
+MYAPP_EMPTY=
+MYAPP_EMPTY_COMMENT= # comment
+MYAPP_HASH=value#not a comment
+MYAPP_SINGLE='literal \n $HOME # not a comment'
+MYAPP_DOUBLE="tab\there \"quoted\" \$HOME" # comment
+MYAPP_MULTI="line one
+line two"
+MYAPP_MULTI_SINGLE='line one
+line two'
+MYAPP_CONTINUED="line \
+continued"
+MYAPP_CONT_SINGLE='line \
+two'
+	export	MYAPP_INDENTED=indented
+MYAPP_RUNTIME=prod
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := constant.MapSource{
+		"MYAPP_RUNTIME":       "prod",
+		"MYAPP_DATABASE_HOST": "db.local",
+		"MYAPP_EMPTY":         "",
+		"MYAPP_EMPTY_COMMENT": "",
+		"MYAPP_HASH":          "value#not a comment",
+		"MYAPP_SINGLE":        `literal \n $HOME # not a comment`,
+		"MYAPP_DOUBLE":        "tab\there \"quoted\" $HOME",
+		"MYAPP_MULTI":         "line one\nline two",
+		"MYAPP_MULTI_SINGLE":  "line one\nline two",
+		"MYAPP_CONTINUED":     "line continued",
+		"MYAPP_CONT_SINGLE":   "line \\\ntwo",
+		"MYAPP_INDENTED":      "indented",
+	}
+	if !reflect.DeepEqual(values, exp) {
+		t.Error(
+			"expected", exp,
+			"got", values,
+		)
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	docs := map[string]string{
+		"A=1\n1B=2\n":             "dotenv: line 2: Invalid key",
+		"A\n":                     "dotenv: line 1: Expected = after A",
+		"A=1\nB=\"unterminated\n": "dotenv: line 2: Unterminated quoted value",
+		"A='x' y\n":               "dotenv: line 1: Unexpected text after quoted value",
+	}
+
+	for doc, exp := range docs {
+		if _, err := constant.ParseDotenv(strings.NewReader(doc)); err == nil || err.Error() != exp {
+			t.Error(
+				"For", doc,
+				"expected", exp,
+				"got", err,
+			)
+		}
+	}
+}
+
+func TestDotenvSource(t *testing.T) {
+	values, _ := constant.ParseDotenv(strings.NewReader("dotenv_HOST=db.local\n"))
+	dotenv_tree := constant.NewTree("dotenv", "_", constant.WithSources(constant.EnvSource{}, values))
+	dotenv_tree.New("HOST", "localhost")
+	dotenv_tree.New("PORT", 3306)
+
+	if str := dotenv_tree.Str("HOST"); str != "db.local" {
+		t.Error(
+			"For", "HOST",
+			"expected", "db.local",
+			"got", str,
+		)
+	}
+	if str := dotenv_tree.Str("PORT"); str != "3306" {
+		t.Error(
+			"For", "PORT",
+			"expected", "3306",
+			"got", str,
+		)
+	}
+}
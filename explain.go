@@ -0,0 +1,101 @@
+package constant
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// An Explanation describes where the effective value of a node came from (see Explain).
+type Explanation struct {
+	// Full name of the node.
+	Name string
+	// The layer the node's raw value came from.
+	// One of "override" (see Set), "env" (EnvSource), "map" (MapSource), "file" (FileSource), "dir" (DirSource), "default" (the node's default value) or "none" if the node has no value.
+	// Other sources are described by their type, for example "*mypackage.VaultSource".
+	Layer string
+	// The source the raw value came from, or nil if it came from the node's default value.
+	Source Source
+	// The file the raw value was read from, either the file of a FileSource or the file named by a <FullName>_FILE value (see WithFileIndirection).
+	File string
+	// The value before templates were executed.
+	Raw string
+	// The effective value of the node, as returned by Eval.
+	// If Secret is true and the value is not empty it is Redacted.
+	Value string
+	// Whether the value is secret, either because the node is secret (see Secret) or because its template references a secret node with a non empty value, through const or json.
+	Secret bool
+	// Explanations of the nodes referenced by const or json in the node's template, in the order they were evaluated.
+	References []*Explanation
+	// The error returned by Eval, if any.
+	Err error
+}
+
+/*
+Returns where the effective value of the node as defined by path came from.
+
+The explanation includes the layer that supplied the raw value, the raw value before templates were executed and, for every node referenced by const in the template, an explanation of that node.
+If the value of the node is evaluated while running Explain the same sources are used for the node and all of its references.
+
+If the node doesn't exist ErrNotExist is returned.
+If the node's value can't be evaluated the explanation is returned along with the error from Eval, so it shows how far evaluation got.
+The raw and effective values of secret nodes (see Secret) are replaced with Redacted.
+So is the effective value of a node whose template references a secret node, even through other nodes.
+*/
+func (n *Node) Explain(path ...string) (*Explanation, error) {
+	node := n.Node(path...)
+	if node == nil {
+		return nil, ErrNotExist
+	}
+
+	e := &Explanation{}
+	_, err := node.eval(nil, nil, e)
+	return e, err
+}
+
+// Returns the name of the layer a source represents (see Explanation.Layer).
+func describeSource(source Source) string {
+	switch source.(type) {
+	case *overrides:
+		return "override"
+	case EnvSource:
+		return "env"
+	case MapSource:
+		return "map"
+	case *FileSource:
+		return "file"
+	case *DirSource:
+		return "dir"
+	}
+	return fmt.Sprintf("%T", source)
+}
+
+// Returns the explanation as an indented tree with one line per node, for example
+//
+//	MYAPP_DATABASE_ADDRESS="mydomain.com:3306" from default `{{ const "HOST" }}:{{ const "PORT" }}`
+//	  MYAPP_DATABASE_HOST="mydomain.com" from env
+//	  MYAPP_DATABASE_PORT="3306" from default
+func (e *Explanation) String() string {
+	var b strings.Builder
+	e.write(&b, 0)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (e *Explanation) write(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(e.Name + "=" + strconv.Quote(e.Value) + " from " + e.Layer)
+	if e.File != "" {
+		b.WriteString(" " + e.File)
+	}
+	if e.Raw != e.Value {
+		b.WriteString(" `" + e.Raw + "`")
+	}
+	if e.Err != nil {
+		b.WriteString(": " + e.Err.Error())
+	}
+	b.WriteString("\n")
+
+	for _, reference := range e.References {
+		reference.write(b, depth+1)
+	}
+}
@@ -0,0 +1,80 @@
+package constant_test
+
+import (
+	"errors"
+	"github.com/JamesStewy/constant"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	dotenv := filepath.Join(dir, ".env")
+	if err := os.WriteFile(dotenv, []byte("explain_DATABASE_PORT=5432\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file_source, err := constant.NewDotenvSource(dotenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	explain_tree := constant.NewTree("explain", "_", constant.WithSources(constant.EnvSource{}, file_source))
+	database, _ := explain_tree.New("DATABASE", nil)
+	database.New("HOST", "localhost")
+	database.New("PORT", 3306)
+	database.New("USER", "admin")
+	database.New("PASSWORD", "hunter2", constant.Secret())
+	database.New("ADDRESS", `{{ const "HOST" }}:{{ const "PORT" }}`)
+	database.New("DSN", `{{ const "USER" }}:{{ const "PASSWORD" }}@{{ const "ADDRESS" }}`)
+
+	os.Setenv("explain_DATABASE_HOST", "mydomain.com")
+	defer os.Unsetenv("explain_DATABASE_HOST")
+	database.Set("root", "USER")
+
+	e, err := database.Explain("DSN")
+	if err != nil {
+		t.Fatal("For DSN expected no error got", err)
+	}
+
+	type result struct{ name, layer, file, raw, value string }
+	results := []struct {
+		e      *constant.Explanation
+		result result
+	}{
+		{e, result{"explain_DATABASE_DSN", "default", "", `{{ const "USER" }}:{{ const "PASSWORD" }}@{{ const "ADDRESS" }}`, constant.Redacted}},
+		{e.References[0], result{"explain_DATABASE_USER", "override", "", "root", "root"}},
+		{e.References[1], result{"explain_DATABASE_PASSWORD", "default", "", constant.Redacted, constant.Redacted}},
+		{e.References[2], result{"explain_DATABASE_ADDRESS", "default", "", `{{ const "HOST" }}:{{ const "PORT" }}`, "mydomain.com:5432"}},
+		{e.References[2].References[0], result{"explain_DATABASE_HOST", "env", "", "mydomain.com", "mydomain.com"}},
+		{e.References[2].References[1], result{"explain_DATABASE_PORT", "file", dotenv, "5432", "5432"}},
+	}
+
+	if len(e.References) != 3 || len(e.References[2].References) != 2 {
+		t.Fatal("For DSN expected 3 references got", e)
+	}
+	for _, r := range results {
+		got := result{r.e.Name, r.e.Layer, r.e.File, r.e.Raw, r.e.Value}
+		if got != r.result {
+			t.Error("For", r.result.name, "expected", r.result, "got", got)
+		}
+	}
+	// DSN isn't secret itself but references PASSWORD
+	if !e.Secret || !e.References[1].Secret || e.References[2].Secret {
+		t.Error("For", "DSN", "expected secret DSN and PASSWORD but not ADDRESS got", e.Secret, e.References[1].Secret, e.References[2].Secret)
+	}
+	if e.References[2].References[1].Source != file_source {
+		t.Error("For explain_DATABASE_PORT expected source", file_source, "got", e.References[2].References[1].Source)
+	}
+
+	if _, err := explain_tree.Explain("MISSING"); err != constant.ErrNotExist {
+		t.Error("For MISSING expected", constant.ErrNotExist, "got", err)
+	}
+
+	database.New("BROKEN", `{{ const "HOST" }}{{ .Missing }`)
+	e, err = database.Explain("BROKEN")
+	var tmpl_err *constant.TemplateError
+	if !errors.As(err, &tmpl_err) || e == nil || e.Err != err || e.Layer != "default" {
+		t.Error("For BROKEN expected explanation with *TemplateError got", e, err)
+	}
+}
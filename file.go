@@ -0,0 +1,72 @@
+package constant
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The suffix of the name which holds the name of a file containing a node's value (see WithFileIndirection).
+const FileSuffix = "_FILE"
+
+// Reads a value from a file, removing a single trailing newline.
+func readValueFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	val := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(val, "\r"), nil
+}
+
+// A DirSource reads values from a directory containing one file per node, such as a Kubernetes ConfigMap or Secret mounted as a volume.
+// A single trailing newline is removed from each file's contents.
+// Files are read every time a node is looked up, so changes to the directory are seen straight away.
+type DirSource struct {
+	dir    string
+	nested bool
+}
+
+// Returns a source which reads the value of a node from the file in dir named by the node's full name.
+// For example the value of MYAPP_DATABASE_HOST is read from dir/MYAPP_DATABASE_HOST.
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+// Returns a source which reads the value of a node from the file at the node's path relative to dir.
+// Child nodes are read from nested directories, for example the value of MYAPP_DATABASE_HOST is read from dir/DATABASE/HOST.
+// A node whose path is a directory has no value.
+func NewNestedDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir, nested: true}
+}
+
+func (d *DirSource) Lookup(path []string, fullName string) (string, bool, error) {
+	var filename string
+	if d.nested {
+		if len(path) == 0 {
+			return "", false, nil
+		}
+		filename = filepath.Join(append([]string{d.dir}, path...)...)
+	} else {
+		if fullName == "" || filepath.Base(fullName) != fullName {
+			return "", false, nil
+		}
+		filename = filepath.Join(d.dir, fullName)
+	}
+
+	info, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) || err == nil && info.IsDir() {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	val, err := readValueFile(filename)
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
@@ -0,0 +1,148 @@
+package constant_test
+
+import (
+	"errors"
+	"github.com/JamesStewy/constant"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileIndirection(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "password"), []byte("hunter2\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "multi"), []byte("line one\nline two\n\n"), 0600)
+
+	file_tree := constant.NewTree("file", "_", constant.WithFileIndirection(), constant.WithSources(
+		constant.EnvSource{},
+		constant.MapSource{"file_MULTI_FILE": filepath.Join(dir, "multi")},
+	))
+	file_tree.New("PASSWORD", "default")
+	file_tree.New("MULTI", "default")
+	file_tree.New("DIRECT", "default")
+	file_tree.New("MISSING", "default")
+
+	os.Setenv("file_PASSWORD_FILE", filepath.Join(dir, "password"))
+	os.Setenv("file_DIRECT", "direct")
+	os.Setenv("file_DIRECT_FILE", filepath.Join(dir, "password"))
+	os.Setenv("file_MISSING_FILE", filepath.Join(dir, "doesntexist"))
+	defer os.Unsetenv("file_PASSWORD_FILE")
+	defer os.Unsetenv("file_DIRECT")
+	defer os.Unsetenv("file_DIRECT_FILE")
+	defer os.Unsetenv("file_MISSING_FILE")
+
+	results := map[string]string{
+		"PASSWORD": "hunter2",
+		"MULTI":    "line one\nline two\n",
+		"DIRECT":   "direct",
+	}
+	for name, exp := range results {
+		if str, err := file_tree.Eval(name); str != exp || err != nil {
+			t.Errorf("For %s expected %q got %q (%v)", name, exp, str, err)
+		}
+	}
+
+	var source_err *constant.SourceError
+	if _, err := file_tree.Eval("MISSING"); !errors.As(err, &source_err) || !errors.Is(err, fs.ErrNotExist) {
+		t.Error(
+			"For", "MISSING",
+			"expected SourceError",
+			"got", err,
+		)
+	}
+
+	plain_tree := constant.NewTree("file", "_")
+	plain_tree.New("PASSWORD", "default")
+	if str := plain_tree.Str("PASSWORD"); str != "default" {
+		t.Error(
+			"For", "PASSWORD without file indirection",
+			"expected", "default",
+			"got", str,
+		)
+	}
+}
+
+func TestFileIndirectionNode(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "hostname"), []byte("myhost\n"), 0600)
+
+	// MYAPP_LOG_FILE is the value of the LOG_FILE node, not the name of a file holding the value of LOG
+	for _, log_file := range []string{filepath.Join(dir, "hostname"), "stdout"} {
+		log_tree := constant.NewTree("MYAPP", "_", constant.WithFileIndirection(), constant.WithSources(
+			constant.MapSource{"MYAPP_LOG_FILE": log_file, "MYAPP_DATABASE_HOST_FILE": filepath.Join(dir, "hostname")},
+		))
+		log_tree.New("LOG", "true")
+		log_tree.New("LOG_FILE", "stderr")
+		database, _ := log_tree.New("DATABASE", nil)
+		database.New("HOST", "localhost")
+		database.New("PORT", "3306")
+
+		if str, err := log_tree.Eval("LOG"); str != "true" || err != nil {
+			t.Errorf("For LOG with MYAPP_LOG_FILE=%s expected %q got %q (%v)", log_file, "true", str, err)
+		}
+		if str := log_tree.Str("LOG_FILE"); str != log_file {
+			t.Errorf("For LOG_FILE expected %q got %q", log_file, str)
+		}
+		if str := log_tree.Str("DATABASE", "HOST"); str != "myhost" {
+			t.Errorf("For DATABASE_HOST expected %q got %q", "myhost", str)
+		}
+		if err := log_tree.Validate(); err != nil {
+			t.Error("For", "Validate with MYAPP_LOG_FILE="+log_file, "expected no error", "got", err)
+		}
+	}
+
+	// A child node can also have the name <FullName>_FILE
+	nested_tree := constant.NewTree("MYAPP", "_", constant.WithFileIndirection(), constant.WithSources(
+		constant.MapSource{"MYAPP_LOG_FILE": "stdout"},
+	))
+	log, _ := nested_tree.New("LOG", "true")
+	log.New("FILE", "stderr")
+	if str, err := nested_tree.Eval("LOG"); str != "true" || err != nil {
+		t.Errorf("For nested LOG expected %q got %q (%v)", "true", str, err)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "dir_DATABASE_HOST"), []byte("db.flat\n"), 0600)
+	os.MkdirAll(filepath.Join(dir, "nested", "DATABASE", "HOST"), 0700)
+	os.WriteFile(filepath.Join(dir, "nested", "DATABASE", "HOST", "PROVIDER"), []byte("external"), 0600)
+	os.WriteFile(filepath.Join(dir, "nested", "DATABASE", "PORT"), []byte("5432\n"), 0600)
+
+	flat_tree := constant.NewTree("dir", "_", constant.WithSources(constant.NewDirSource(dir)))
+	nested_tree := constant.NewTree("dir", "_", constant.WithSources(constant.NewNestedDirSource(filepath.Join(dir, "nested"))))
+	for _, dir_tree := range []*constant.Node{flat_tree, nested_tree} {
+		database, _ := dir_tree.New("DATABASE", nil)
+		database.New("HOST", "localhost")
+		database.Node("HOST").New("PROVIDER", "internal")
+		database.New("PORT", 3306)
+	}
+
+	results := []struct {
+		tree *constant.Node
+		path []string
+		exp  string
+	}{
+		{flat_tree, []string{"DATABASE", "HOST"}, "db.flat"},
+		{flat_tree, []string{"DATABASE", "HOST", "PROVIDER"}, "internal"},
+		{flat_tree, []string{"DATABASE", "PORT"}, "3306"},
+		{nested_tree, []string{"DATABASE", "HOST"}, "localhost"},
+		{nested_tree, []string{"DATABASE", "HOST", "PROVIDER"}, "external"},
+		{nested_tree, []string{"DATABASE", "PORT"}, "5432"},
+	}
+	for _, test := range results {
+		if str, err := test.tree.Eval(test.path...); str != test.exp || err != nil {
+			t.Errorf("For %v expected %q got %q (%v)", test.path, test.exp, str, err)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "dir_DATABASE_PORT"), []byte("6543"), 0600)
+	if str := flat_tree.Str("DATABASE", "PORT"); str != "6543" {
+		t.Error(
+			"For", "DATABASE_PORT after writing file",
+			"expected", "6543",
+			"got", str,
+		)
+	}
+}
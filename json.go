@@ -0,0 +1,135 @@
+package constant
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// Creates child nodes of n from the JSON object read from r.
+//
+// Every key in the object becomes a child node of n.
+// Nested objects become child nodes with a nil default value (like LOG in the package example), and their keys become the children of that node.
+// To give a node created from a nested object a default value use an empty key in the nested object.
+// Strings, numbers and booleans become default values in the same way as New converts string, int, float64 and bool.
+// null becomes a nil default value.
+//
+// If a node for a nested object already exists the nested object is loaded into the existing node.
+// Otherwise nodes that already exist are not changed.
+// Every key that can't be loaded (for example because it is not a valid name) is reported as a *LoadError, joined together with errors.Join.
+//
+// For example the following JSON creates the same tree as the package example.
+//
+//	{
+//		"LOG": {"LEVEL": 5, "FILE": "stdout"},
+//		"RUNTIME": "dev",
+//		"DATABASE": {
+//			"": true,
+//			"HOST": {"": "localhost", "PROVIDER": "internal"},
+//			"PORT": 3306,
+//			"ADDRESS": "{{ const \"HOST\" }}:{{ const \"PORT\" }}"
+//		}
+//	}
+func (n *Node) LoadJSON(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return err
+	}
+
+	return errors.Join(n.load(nil, jsonValues(values).(map[string]interface{}))...)
+}
+
+// Creates child nodes of n from the JSON object in the named file.
+// See LoadJSON for details.
+func (n *Node) LoadJSONFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return n.LoadJSON(file)
+}
+
+// Converts json.Number values to int or float64.
+func jsonValues(value interface{}) interface{} {
+	switch t := value.(type) {
+	case json.Number:
+		if val, err := t.Int64(); err == nil && int64(int(val)) == val {
+			return int(val)
+		}
+		if val, err := t.Float64(); err == nil {
+			return val
+		}
+		return t.String()
+	case map[string]interface{}:
+		for key, val := range t {
+			t[key] = jsonValues(val)
+		}
+	}
+	return value
+}
+
+// Decodes the value of n.Str(path...) as JSON into the value pointed to by target.
+//
+// Follows convention of json.Unmarshal (https://golang.org/pkg/encoding/json/#Unmarshal).
+// Templates in the value are executed before it is decoded, so a node can build its JSON from other nodes (see the json template function).
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) JSON(target interface{}, path ...string) error {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(str), target)
+}
+
+// Evaluates the node for the json template function, as a string if it has no child nodes or as an object with a key for each child node.
+// The node's own value is included in the object under an empty key if the node has a value.
+// skip is the node whose template is being executed, which is left out of the object.
+// chain, sources and trace are as for eval.
+// Every node in the object is added to the references of trace, so that a secret anywhere in the object makes the value of the node being executed secret (see Explanation.Secret).
+func (n *Node) jsonValue(chain []*Node, sources []Source, skip *Node, trace *Explanation) (interface{}, error) {
+	reference := &Explanation{}
+	if trace != nil {
+		trace.References = append(trace.References, reference)
+	}
+	val, err := n.eval(chain, sources, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	children := n.children()
+	if len(children) == 0 {
+		return val, nil
+	}
+
+	object := make(map[string]interface{}, len(children)+1)
+	if reference.Layer != "none" {
+		object[""] = val
+	}
+	for _, child := range children {
+		if child == skip {
+			continue
+		}
+		if object[child.name], err = child.jsonValue(chain, sources, skip, trace); err != nil {
+			return nil, err
+		}
+	}
+	return object, nil
+}
+
+// Returns val encoded as JSON without escaping HTML characters.
+func encodeJSON(val interface{}) (string, error) {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(val); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
@@ -0,0 +1,71 @@
+package constant
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// A LoadError is returned when a value in a document (or a field of a struct passed to Register) can't be loaded into a tree.
+type LoadError struct {
+	// Path of the value in the document.
+	Path []string
+	Err  error
+}
+
+func (e *LoadError) Error() string {
+	return "Unable to load " + strings.Join(e.Path, ".") + ": " + e.Err.Error()
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// Creates child nodes of n from a decoded document.
+//
+// Each key in values becomes a child node.
+// Nested maps become child nodes with a nil default value, unless the nested map has an empty key in which case its value is used as the default value.
+// Other values are passed to New as the node's default value.
+// If a node for a nested map already exists the nested map is loaded into the existing node.
+//
+// Returns a *LoadError for every value which couldn't be loaded.
+func (n *Node) load(path []string, values map[string]interface{}) []error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		key_path := append(path[:len(path):len(path)], key)
+
+		if key == "" {
+			errs = append(errs, &LoadError{key_path, errors.New("Can't set value of existing node")})
+			continue
+		}
+
+		child_values, is_map := values[key].(map[string]interface{})
+		if !is_map {
+			if _, err := n.New(key, values[key]); err != nil {
+				errs = append(errs, &LoadError{key_path, err})
+			}
+			continue
+		}
+
+		def_val, has_def := child_values[""]
+		child := n.Node(key)
+		if child == nil || has_def {
+			var err error
+			if child, err = n.New(key, def_val); err != nil {
+				errs = append(errs, &LoadError{key_path, err})
+				continue
+			}
+		}
+
+		delete(child_values, "")
+		errs = append(errs, child.load(key_path, child_values)...)
+	}
+
+	return errs
+}
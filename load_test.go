@@ -0,0 +1,422 @@
+package constant_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/JamesStewy/constant"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var load_json = `{
+	"LOG": {"LEVEL": 5, "FILE": "stdout"},
+	"RUNTIME": "dev",
+	"DATABASE": {
+		"": true,
+		"HOST": {"": "localhost", "PROVIDER": "internal"},
+		"PORT": 3306,
+		"RATIO": 0.25,
+		"ADDRESS": "{{ const \"HOST\" }}:{{ const \"PORT\" }}",
+		"PASSWORD": null
+	}
+}`
+
+var load_results = map[string]string{
+	"MYAPP_LOG_LEVEL":              "5",
+	"MYAPP_LOG_FILE":               "stdout",
+	"MYAPP_RUNTIME":                "dev",
+	"MYAPP_DATABASE":               "true",
+	"MYAPP_DATABASE_HOST":          "localhost",
+	"MYAPP_DATABASE_HOST_PROVIDER": "internal",
+	"MYAPP_DATABASE_PORT":          "3306",
+	"MYAPP_DATABASE_RATIO":         "0.25",
+	"MYAPP_DATABASE_ADDRESS":       "localhost:3306",
+}
+
+func checkLoaded(t *testing.T, load_tree *constant.Node) {
+	res := make(map[string]string)
+	for _, node := range load_tree.Nodes() {
+		res[node.FullName()] = node.Str()
+	}
+	if !reflect.DeepEqual(res, load_results) {
+		t.Error(
+			"expected", load_results,
+			"got", res,
+		)
+	}
+
+	if load_tree.Node("DATABASE", "PASSWORD") == nil {
+		t.Error(
+			"For", "DATABASE_PASSWORD",
+			"expected node",
+			"got", nil,
+		)
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	load_tree := constant.NewTree("MYAPP", "_")
+	if err := load_tree.LoadJSON(strings.NewReader(load_json)); err != nil {
+		t.Fatal(err)
+	}
+	checkLoaded(t, load_tree)
+}
+
+func TestJSON(t *testing.T) {
+	json_tree := constant.NewTree("MYAPP", "_")
+	json_tree.LoadJSON(strings.NewReader(load_json))
+	json_tree.New("DATABASE_JSON", `{{ json "DATABASE" }}`)
+	json_tree.Node("DATABASE").New("SELF", `{{ json "" }}`)
+	json_tree.New("MISSING_JSON", `{{ json "MISSING" }}`)
+	json_tree.New("ROUTES", `{"default": "{{ const "RUNTIME" }}", "weights": [1, 2], "log": {{ json "LOG" "FILE" }}}`)
+
+	exp := `{"":"true","ADDRESS":"localhost:3306","HOST":{"":"localhost","PROVIDER":"internal"},"PASSWORD":"","PORT":"3306","RATIO":"0.25"}`
+	self, _ := json.Marshal(exp)
+	results := map[string]string{
+		// The value of SELF is a string of JSON like any other value
+		"DATABASE_JSON": strings.Replace(exp, `"RATIO":"0.25"`, `"RATIO":"0.25","SELF":`+string(self), 1),
+		"MISSING_JSON":  "null",
+	}
+	for name, exp := range results {
+		if str, err := json_tree.Eval(name); str != exp || err != nil {
+			t.Error(
+				"For", name,
+				"expected", exp,
+				"got (", str, err, ")",
+			)
+		}
+	}
+
+	if str, err := json_tree.Eval("DATABASE", "SELF"); str != exp || err != nil {
+		t.Error("For", "DATABASE_SELF", "expected", exp, "got (", str, err, ")")
+	}
+
+	var routes struct {
+		Default string
+		Weights []int
+		Log     string
+	}
+	if err := json_tree.JSON(&routes, "ROUTES"); err != nil || routes.Default != "dev" || !reflect.DeepEqual(routes.Weights, []int{1, 2}) || routes.Log != "stdout" {
+		t.Error("For", "ROUTES", "expected", "{dev [1 2] stdout}", "got (", routes, err, ")")
+	}
+
+	var database map[string]interface{}
+	if err := json_tree.JSON(&database, "DATABASE_JSON"); err != nil || database["PORT"] != "3306" {
+		t.Error("For", "DATABASE_JSON", "expected PORT 3306 got (", database, err, ")")
+	}
+	if err := json_tree.JSON(&database, "RUNTIME"); err == nil {
+		t.Error("For", "RUNTIME", "expected error", "got", nil)
+	}
+	if err := json_tree.JSON(&database, "NOTEXIST"); err != constant.ErrNotExist {
+		t.Error("For", "NOTEXIST", "expected", constant.ErrNotExist, "got", err)
+	}
+}
+
+func TestJSONSecret(t *testing.T) {
+	json_tree := constant.NewTree("MYAPP", "_")
+	json_tree.LoadJSON(strings.NewReader(load_json))
+	json_tree.Node("DATABASE").New("TOKEN", "", constant.Secret())
+	json_tree.New("DATABASE_JSON", `{{ json "DATABASE" }}`)
+	json_tree.New("HOST_JSON", `{{ json "DATABASE" "HOST" }}`)
+
+	// Empty secrets don't make the value secret
+	if str := json_tree.Node("DATABASE_JSON").String(); str == constant.Redacted || !strings.Contains(str, `"PORT":"3306"`) {
+		t.Error("For", "DATABASE_JSON", "expected JSON got", str)
+	}
+
+	json_tree.Set("hunter2", "DATABASE", "PASSWORD")
+	json_tree.Conceal("DATABASE", "PASSWORD")
+	if str := json_tree.Str("DATABASE_JSON"); !strings.Contains(str, `"PASSWORD":"hunter2"`) {
+		t.Error("For Str", "DATABASE_JSON", "expected", `"PASSWORD":"hunter2"`, "got", str)
+	}
+	if str := json_tree.Node("DATABASE_JSON").String(); str != constant.Redacted {
+		t.Error("For String", "DATABASE_JSON", "expected", constant.Redacted, "got", str)
+	}
+	for _, line := range json_tree.Dump() {
+		if strings.Contains(line, "hunter2") {
+			t.Error("For Dump", "expected redacted values got", line)
+		}
+	}
+	if e, _ := json_tree.Explain("DATABASE_JSON"); !e.Secret || e.Value != constant.Redacted {
+		t.Error("For Explain", "DATABASE_JSON", "expected", constant.Redacted, "got", e)
+	}
+	// HOST's subtree has no secrets
+	if str := json_tree.Node("HOST_JSON").String(); str != `{"":"localhost","PROVIDER":"internal"}` {
+		t.Error("For String", "HOST_JSON", "expected", `{"":"localhost","PROVIDER":"internal"}`, "got", str)
+	}
+}
+
+func TestLoadJSONErrors(t *testing.T) {
+	load_tree := constant.NewTree("MYAPP", "_")
+	load_tree.New("EXISTS", "value")
+
+	err := load_tree.LoadJSON(strings.NewReader(`{
+		"EXISTS": "again",
+		"valid": {"2invalid": 1, "also-invalid": {"x": 1}, "fine": 2},
+		"list": [1, 2]
+	}`))
+
+	var paths []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var load_err *constant.LoadError
+		if !errors.As(err, &load_err) {
+			t.Fatal("expected LoadError got", err)
+		}
+		paths = append(paths, strings.Join(load_err.Path, "."))
+	}
+
+	exp_paths := []string{"EXISTS", "list", "valid.2invalid", "valid.also-invalid"}
+	if !reflect.DeepEqual(paths, exp_paths) {
+		t.Error(
+			"expected", exp_paths,
+			"got", paths,
+		)
+	}
+
+	if str := load_tree.Str("valid", "fine"); str != "2" {
+		t.Error(
+			"For", "valid_fine",
+			"expected", "2",
+			"got", str,
+		)
+	}
+}
+
+var load_yaml = `# Example tree
+---
+LOG:
+  LEVEL: 5
+  FILE: stdout   # comment
+RUNTIME: "dev"
+DATABASE:
+  "": true
+  HOST:
+    "": localhost
+    PROVIDER: 'internal'
+  PORT: 3306
+  RATIO: 0.25
+  ADDRESS: '{{ const "HOST" }}:{{ const "PORT" }}'
+  PASSWORD:
+`
+
+func TestLoadYAML(t *testing.T) {
+	load_tree := constant.NewTree("MYAPP", "_")
+	if err := load_tree.LoadYAML(strings.NewReader(load_yaml)); err != nil {
+		t.Fatal(err)
+	}
+	checkLoaded(t, load_tree)
+}
+
+func TestLoadYAMLScalars(t *testing.T) {
+	load_tree := constant.NewTree("MYAPP", "_")
+	err := load_tree.LoadYAML(strings.NewReader(`
+plain: hello world
+colon: localhost:3306
+double: "tab\there \"quoted\" \u00e9"
+single: 'it''s'
+null_value: ~
+float: 1.50
+literal: |
+  line one
+    line two
+
+folded: >-
+  folded
+  text
+
+  paragraph
+keep: |+
+  kept
+
+strip: |-
+  stripped
+last: end
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := map[string]string{
+		"plain":   "hello world",
+		"colon":   "localhost:3306",
+		"double":  "tab\there \"quoted\" \u00e9",
+		"single":  "it's",
+		"float":   "1.5",
+		"literal": "line one\n  line two\n",
+		"folded":  "folded text\nparagraph",
+		"keep":    "kept\n\n",
+		"strip":   "stripped",
+		"last":    "end",
+	}
+	for name, exp := range results {
+		if str := load_tree.Default(name); str != exp {
+			t.Errorf("For %s expected %q got %q", name, exp, str)
+		}
+	}
+	if load_tree.Node("null_value") == nil || load_tree.IsSet("null_value") {
+		t.Error("For null_value expected node without value")
+	}
+}
+
+func TestLoadYAMLSyntaxErrors(t *testing.T) {
+	docs := []string{
+		"a:\n  - 1\n",
+		"a: [1, 2]\n",
+		"a: &anchor 1\n",
+		"a: 1\n  b: 2\n",
+		"a: 1\na: 2\n",
+		"a: \"unterminated\n",
+		"just a string\n",
+		"a:\n\tb: 1\n",
+	}
+
+	for _, doc := range docs {
+		load_tree := constant.NewTree("MYAPP", "_")
+		if err := load_tree.LoadYAML(strings.NewReader(doc)); err == nil {
+			t.Errorf("For %q expected error got nil", doc)
+		}
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	load_tree := constant.NewTree("MYAPP", "_")
+	load_tree.LoadYAML(strings.NewReader(load_yaml))
+	load_tree.Node("LOG").New("FORMAT", "1.50")
+	load_tree.Node("LOG").New("COLOR", "yes: no\n")
+
+	var defaults bytes.Buffer
+	if err := load_tree.WriteDefaultsYAML(&defaults); err != nil {
+		t.Fatal(err)
+	}
+
+	reload_tree := constant.NewTree("MYAPP", "_")
+	if err := reload_tree.LoadYAML(&defaults); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, node := range load_tree.Nodes() {
+		name := strings.TrimPrefix(node.FullName(), "MYAPP_")
+		reloaded := reload_tree.Node(strings.Split(name, "_")...)
+		if reloaded == nil || reloaded.Default() != node.Default() {
+			t.Error(
+				"For", name,
+				"expected", node.Default(),
+				"got", reloaded,
+			)
+		}
+	}
+
+	var effective bytes.Buffer
+	if err := load_tree.WriteYAML(&effective); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(effective.String(), "  ADDRESS: localhost:3306\n") {
+		t.Error(
+			"expected", "  ADDRESS: localhost:3306",
+			"got", effective.String(),
+		)
+	}
+}
+
+func TestWriteYAMLScalars(t *testing.T) {
+	// Plain scalars which other YAML parsers would read as something other than the same string are quoted
+	scalars := []struct{ name, val, exp string }{
+		{"HEX", "0x10", "'0x10'"},
+		{"OCTAL", "0o17", "'0o17'"},
+		{"BINARY", "0b101", "'0b101'"},
+		{"GROUPED", "1_000", "'1_000'"},
+		{"GROUPED_FLOAT", "1_000.5", "'1_000.5'"},
+		{"SEXAGESIMAL", "1:30", "'1:30'"},
+		{"INF", ".inf", "'.inf'"},
+		{"NAN", ".NaN", "'.NaN'"},
+		{"YES_LOWER", "yes", "'yes'"},
+		{"Y_LOWER", "y", "'y'"},
+		{"OFF_UPPER", "OFF", "'OFF'"},
+		{"DATE", "2016-01-02", "'2016-01-02'"},
+		{"DASH", "-", "'-'"},
+		{"NULL_LOWER", "null", "'null'"},
+		{"AT", "@x", "'@x'"},
+		{"AT_INSIDE", "a@b", "a@b"},
+		{"NULL_UPPER", "NULL", "'NULL'"},
+		{"PLAIN", "localhost", "localhost"},
+		{"NUMBER", "3306", "3306"},
+		{"DASHED", "a-b", "a-b"},
+	}
+
+	scalar_tree := constant.NewTree("MYAPP", "_")
+	for _, scalar := range scalars {
+		scalar_tree.New(scalar.name, scalar.val)
+	}
+
+	var buf bytes.Buffer
+	if err := scalar_tree.WriteYAML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, scalar := range scalars {
+		if !strings.Contains(buf.String(), "\n"+scalar.name+": "+scalar.exp+"\n") && !strings.HasPrefix(buf.String(), scalar.name+": "+scalar.exp+"\n") {
+			t.Error(
+				"For", scalar.name,
+				"expected", scalar.name+": "+scalar.exp,
+				"got", buf.String(),
+			)
+		}
+	}
+
+	reload_tree := constant.NewTree("MYAPP", "_")
+	if err := reload_tree.LoadYAML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, scalar := range scalars {
+		if str := reload_tree.Str(scalar.name); str != scalar.val {
+			t.Error("For", scalar.name, "expected", scalar.val, "got", str)
+		}
+	}
+}
+
+func TestWriteYAMLKeys(t *testing.T) {
+	// Node names which YAML parsers would read as booleans or null are quoted
+	keys := []struct{ name, exp string }{
+		{"yes", "'yes'"},
+		{"on", "'on'"},
+		{"Y", "'Y'"},
+		{"null", "'null'"},
+		{"true", "'true'"},
+		{"False", "'False'"},
+		{"name", "name"},
+	}
+
+	key_tree := constant.NewTree("MYAPP", "_")
+	for _, key := range keys {
+		key_tree.New(key.name, "value")
+	}
+	parent, _ := key_tree.New("off", nil)
+	parent.New("NULL", nil)
+
+	var buf bytes.Buffer
+	if err := key_tree.WriteYAML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if !strings.Contains(buf.String(), key.exp+": value\n") {
+			t.Error("For", key.name, "expected", key.exp+": value", "got", buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), "'off':\n  'NULL': null\n") {
+		t.Error("For", "off", "expected", "'off':\n  'NULL': null", "got", buf.String())
+	}
+
+	reload_tree := constant.NewTree("MYAPP", "_")
+	if err := reload_tree.LoadYAML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if str := reload_tree.Str(key.name); str != "value" {
+			t.Error("For", key.name, "expected", "value", "got", str)
+		}
+	}
+	if reload_tree.Node("off", "NULL") == nil {
+		t.Error("For", "off NULL", "expected node", "got", nil)
+	}
+}
@@ -0,0 +1,253 @@
+package constant
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A KeyError is returned when the value of an entry in a map value can't be converted.
+type KeyError struct {
+	// Key of the entry.
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return "Key " + strconv.Quote(e.Key) + ": " + e.Err.Error()
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// The separator New uses between the key and value of each entry of map default values.
+// Entries are separated by ListSeparator.
+const MapSeparator = "="
+
+/*
+Splits str into the entries of a map, with entries separated by sep and each key separated from its value by the first kvSep.
+
+Whitespace around each key and value is removed and empty entries are skipped.
+A backslash before sep, kvSep, a backslash or a whitespace character (space, tab, carriage return or newline) makes that text part of the key or value, other backslashes are kept as they are.
+For example with sep "," and kvSep "=" the value
+
+	team=core, query=a\,b=c , path=C:\data\ ,
+
+has the entries "team" = "core", "query" = "a,b=c" and "path" = "C:\data ".
+
+An entry without kvSep, an entry with an empty key or a repeated key is reported as an *ElementError with the index of the entry.
+*/
+func splitMap(str, sep, kvSep string) (map[string]string, error) {
+	if sep == "" || kvSep == "" {
+		return nil, errors.New("Empty separator")
+	}
+
+	entries := make(map[string]string)
+	var key, val mapText
+	in_val := false
+	index := 0
+
+	end_entry := func() error {
+		defer func() {
+			key, val, in_val = mapText{}, mapText{}, false
+			index++
+		}()
+
+		if !in_val {
+			if key.String() == "" {
+				return nil
+			}
+			return &ElementError{index, errors.New("Missing separator " + strconv.Quote(kvSep))}
+		}
+		k := key.String()
+		if k == "" {
+			return &ElementError{index, errors.New("Empty key")}
+		}
+		if _, exists := entries[k]; exists {
+			return &ElementError{index, errors.New("Duplicate key " + strconv.Quote(k))}
+		}
+		entries[k] = val.String()
+		return nil
+	}
+
+	for i := 0; i < len(str); {
+		text := &key
+		if in_val {
+			text = &val
+		}
+
+		rest := str[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1:
+			next, escaped := rest[1:], ""
+			switch {
+			case strings.HasPrefix(next, sep):
+				escaped = sep
+			case strings.HasPrefix(next, kvSep):
+				escaped = kvSep
+			case strings.ContainsAny(next[:1], "\\ \t\r\n"):
+				escaped = next[:1]
+			}
+			if escaped != "" {
+				text.write(escaped, true)
+				i += 1 + len(escaped)
+			} else {
+				text.write("\\", false)
+				i++
+			}
+		case strings.HasPrefix(rest, sep):
+			if err := end_entry(); err != nil {
+				return nil, err
+			}
+			i += len(sep)
+		case !in_val && strings.HasPrefix(rest, kvSep):
+			in_val = true
+			i += len(kvSep)
+		default:
+			text.write(rest[:1], false)
+			i++
+		}
+	}
+
+	if err := end_entry(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// A key or value of a map entry being read by splitMap.
+type mapText struct {
+	text []byte
+	// Length of text up to the end of its last character which is not unescaped whitespace.
+	end int
+}
+
+// Adds str to the text.
+// Unescaped whitespace at the start of the text is skipped.
+func (t *mapText) write(str string, escaped bool) {
+	space := !escaped && strings.TrimLeft(str, " \t\r\n") == ""
+	if space && len(t.text) == 0 {
+		return
+	}
+	t.text = append(t.text, str...)
+	if !space {
+		t.end = len(t.text)
+	}
+}
+
+func (t *mapText) String() string {
+	return string(t.text[:t.end])
+}
+
+// Joins the entries of m, sorted by key, into a map value that splitMap reads back with the same separators.
+func joinMap(m map[string]string, sep, kvSep string) string {
+	keys := sortedKeys(m)
+	entries := make([]string, len(keys))
+	for i, key := range keys {
+		entries[i] = escapeMapText(key, sep, kvSep) + kvSep + escapeMapText(m[key], sep, kvSep)
+	}
+	return strings.Join(entries, sep)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Escapes backslashes, separators and whitespace at either end of str (see splitMap).
+func escapeMapText(str, sep, kvSep string) string {
+	str = strings.NewReplacer("\\", "\\\\", sep, "\\"+sep, kvSep, "\\"+kvSep).Replace(str)
+
+	trimmed := strings.TrimLeft(str, " \t\r\n")
+	leading := str[:len(str)-len(trimmed)]
+	str = trimmed
+	trimmed = strings.TrimRight(str, " \t\r\n")
+	trailing := str[len(trimmed):]
+
+	escape := func(space string) string {
+		var b strings.Builder
+		for _, c := range space {
+			b.WriteString("\\" + string(c))
+		}
+		return b.String()
+	}
+	return escape(leading) + trimmed + escape(trailing)
+}
+
+// Returns the value of n.Str(path...) as a map of strings.
+//
+// Entries are separated by sep and each key is separated from its value by the first kvSep, for example "team=core, tier=1" with sep "," and kvSep "=".
+// Whitespace around keys and values is removed, and a backslash escapes a following sep, kvSep, backslash or whitespace character.
+// An empty value is an empty map.
+// If an entry can't be read an *ElementError with the entry's index is returned.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) StrMap(sep, kvSep string, path ...string) (val map[string]string, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = splitMap(str, sep, kvSep)
+	return
+}
+
+// Run n.StrMap(sep, kvSep, path...) but ignore errors
+func (n *Node) StrMapI(sep, kvSep string, path ...string) (val map[string]string) {
+	val, _ = n.StrMap(sep, kvSep, path...)
+	return
+}
+
+// Returns the value of n.StrMap(sep, kvSep, path...) as a map of integers.
+//
+// Each value follows convention of strconv.Atoi (https://golang.org/pkg/strconv/#Atoi).
+// The first value, in order of keys, that can't be converted is reported as a *KeyError with the entry's key.
+func (n *Node) IntMap(sep, kvSep string, path ...string) (map[string]int, error) {
+	entries, err := n.StrMap(sep, kvSep, path...)
+	if err != nil {
+		return nil, err
+	}
+
+	val := make(map[string]int, len(entries))
+	for _, key := range sortedKeys(entries) {
+		if val[key], err = strconv.Atoi(entries[key]); err != nil {
+			return nil, &KeyError{key, err}
+		}
+	}
+	return val, nil
+}
+
+// Run n.IntMap(sep, kvSep, path...) but ignore errors
+func (n *Node) IntMapI(sep, kvSep string, path ...string) (val map[string]int) {
+	val, _ = n.IntMap(sep, kvSep, path...)
+	return
+}
+
+// Returns the value of n.StrMap(sep, kvSep, path...) as a map of booleans.
+//
+// Each value follows convention of strconv.ParseBool (https://golang.org/pkg/strconv/#ParseBool).
+// The first value, in order of keys, that can't be converted is reported as a *KeyError with the entry's key.
+func (n *Node) BoolMap(sep, kvSep string, path ...string) (map[string]bool, error) {
+	entries, err := n.StrMap(sep, kvSep, path...)
+	if err != nil {
+		return nil, err
+	}
+
+	val := make(map[string]bool, len(entries))
+	for _, key := range sortedKeys(entries) {
+		if val[key], err = strconv.ParseBool(entries[key]); err != nil {
+			return nil, &KeyError{key, err}
+		}
+	}
+	return val, nil
+}
+
+// Run n.BoolMap(sep, kvSep, path...) but ignore errors
+func (n *Node) BoolMapI(sep, kvSep string, path ...string) (val map[string]bool) {
+	val, _ = n.BoolMap(sep, kvSep, path...)
+	return
+}
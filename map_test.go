@@ -0,0 +1,86 @@
+package constant_test
+
+import (
+	"errors"
+	"github.com/JamesStewy/constant"
+	"reflect"
+	"testing"
+)
+
+func TestStrMap(t *testing.T) {
+	map_tree := constant.NewTree("map", "_")
+	map_tree.New("LABELS", "team=core, tier = 1 ,")
+	map_tree.New("ESCAPED", `query=a\,b=c, path=C:\data\ , a\=b=\\`)
+	map_tree.New("HEADERS", "Accept: text/plain; X-Id: 7")
+	map_tree.New("EMPTY", "")
+	map_tree.New("DEFAULT", map[string]string{"b": "2", "a": "1", "c,d": "e=f", " g": `h\ `})
+	map_tree.New("NEWLINES", map[string]string{"n": "x\n", "r": "\r\ny", "t": "\tz\t"})
+	map_tree.New("MISSING_SEP", "a=1, b")
+	map_tree.New("EMPTY_KEY", "a=1, =2")
+	map_tree.New("DUPLICATE", "a=1, a=2")
+
+	results := []struct {
+		name  string
+		sep   string
+		kvSep string
+		val   map[string]string
+		err   bool
+	}{
+		{"LABELS", ",", "=", map[string]string{"team": "core", "tier": "1"}, false},
+		{"ESCAPED", ",", "=", map[string]string{"query": "a,b=c", "path": `C:\data `, "a=b": `\`}, false},
+		{"HEADERS", ";", ":", map[string]string{"Accept": "text/plain", "X-Id": "7"}, false},
+		{"EMPTY", ",", "=", map[string]string{}, false},
+		{"DEFAULT", ",", "=", map[string]string{"a": "1", "b": "2", "c,d": "e=f", " g": `h\ `}, false},
+		{"NEWLINES", ",", "=", map[string]string{"n": "x\n", "r": "\r\ny", "t": "\tz\t"}, false},
+		{"MISSING_SEP", ",", "=", nil, true},
+		{"EMPTY_KEY", ",", "=", nil, true},
+		{"DUPLICATE", ",", "=", nil, true},
+		{"LABELS", "", "=", nil, true},
+		{"MISSING", ",", "=", nil, true},
+	}
+
+	for _, r := range results {
+		val, err := map_tree.StrMap(r.sep, r.kvSep, r.name)
+		if !reflect.DeepEqual(val, r.val) || (err != nil) != r.err {
+			t.Error(
+				"For", r.name,
+				"expected", r.val, r.err,
+				"got (", val, err, ")",
+			)
+		}
+	}
+
+	if str := map_tree.Str("DEFAULT"); str != `\ g=h\\\ ,a=1,b=2,c\,d=e\=f` {
+		t.Error("For", "DEFAULT", "expected", `\ g=h\\\ ,a=1,b=2,c\,d=e\=f`, "got", str)
+	}
+
+	var elem_err *constant.ElementError
+	if _, err := map_tree.StrMap(",", "=", "DUPLICATE"); !errors.As(err, &elem_err) || elem_err.Index != 1 {
+		t.Error("For", "DUPLICATE", "expected *ElementError at index 1 got", err)
+	}
+}
+
+func TestTypedMap(t *testing.T) {
+	map_tree := constant.NewTree("map", "_")
+	map_tree.New("LIMITS", "acme=100, globex=250")
+	map_tree.New("FLAGS", "beta=true, legacy=0")
+	map_tree.New("INVALID", "a=1, b=two, c=three")
+
+	if val, err := map_tree.IntMap(",", "=", "LIMITS"); !reflect.DeepEqual(val, map[string]int{"acme": 100, "globex": 250}) || err != nil {
+		t.Error("For", "LIMITS", "expected", map[string]int{"acme": 100, "globex": 250}, "got (", val, err, ")")
+	}
+	if val := map_tree.BoolMapI(",", "=", "FLAGS"); !reflect.DeepEqual(val, map[string]bool{"beta": true, "legacy": false}) {
+		t.Error("For", "FLAGS", "expected", map[string]bool{"beta": true, "legacy": false}, "got", val)
+	}
+
+	var key_err *constant.KeyError
+	if val, err := map_tree.IntMap(",", "=", "INVALID"); !errors.As(err, &key_err) || key_err.Key != "b" {
+		t.Error("For", "INVALID", "expected *KeyError for key b got (", val, err, ")")
+	}
+	if _, err := map_tree.BoolMap(",", "=", "LIMITS"); !errors.As(err, &key_err) || key_err.Key != "acme" {
+		t.Error("For", "LIMITS", "expected *KeyError for key acme got", err)
+	}
+	if val := map_tree.IntMapI(",", "=", "INVALID"); val != nil {
+		t.Error("For", "INVALID", "expected", nil, "got", val)
+	}
+}
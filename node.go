@@ -1,14 +1,21 @@
 package constant
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"sync"
+	"text/template"
+	"time"
 )
 
+// Returned when the node as defined by path does not exist.
+var ErrNotExist = errors.New("Does not exist")
+
 // A Node represents one node in a tree of constants.
 // A Node can have a value and/or child nodes associated with it.
 type Node struct {
@@ -18,17 +25,38 @@ type Node struct {
 	def_val   *string
 	parent    *Node
 	nodes     map[string]*Node
+	config    *treeConfig
+	required  bool
+	secret    bool
+
+	validators  []Validator
+	constraints []Constraint
+
+	tmpl_mutex sync.Mutex
+	tmpl_src   string
+	tmpl       *template.Template
 }
 
 // Creates the root node for a new tree.
 //
 // Prefix sets the environment variable prefix which is prepended to node names when searching the runtime environment.
 // For example if a tree has a prefix 'MYSQL', a delimiter of '_' and a child node named 'HOST' then constant 'HOST' would be set to the value of the environment variable 'MYSQL_HOST'.
-func NewTree(prefix, delimiter string) *Node {
+//
+// Options change how the tree looks up values (see Option).
+func NewTree(prefix, delimiter string, options ...Option) *Node {
+	config := &treeConfig{
+		sources:        []Source{EnvSource{}},
+		watch_interval: DefaultWatchInterval,
+	}
+	for _, option := range options {
+		option(config)
+	}
+
 	return &Node{
 		name:      prefix,
 		delimiter: delimiter,
 		nodes:     make(map[string]*Node),
+		config:    config,
 	}
 }
 
@@ -46,13 +74,24 @@ def_val: The default value for the constant if no environment variable is availa
 def_val must be one of the following types:
 	string
 	[]byte
+	time.Duration (stored as by Duration.String, for example "1m30s")
+	time.Time (stored in the time.RFC3339Nano layout)
 	fmt.Stringer (https://golang.org/pkg/fmt/#Stringer)
+	encoding.TextMarshaler (https://golang.org/pkg/encoding/#TextMarshaler)
 	int
 	float64
 	bool
+	any other signed or unsigned integer or float type, including named types such as `type Port uint16`
+	complex64 or complex128 (stored as by strconv.FormatComplex, for example "(1+2i)")
+	[]string (stored as a list separated by ListSeparator, see StrSlice)
+	[]int (stored as a list separated by ListSeparator, see IntSlice)
+	map[string]string (stored sorted by key as entries separated by ListSeparator, with MapSeparator between each key and value, see StrMap)
+	a pointer to one of the above types, or a pointer to such a pointer (a nil pointer at any depth is the same as nil)
 	nil (no default value: the new child node will act purely as a node)
+
+options: Optional settings for the new node (see NodeOption).
 */
-func (n *Node) New(name string, def_val interface{}) (*Node, error) {
+func (n *Node) New(name string, def_val interface{}, options ...NodeOption) (*Node, error) {
 	if !valid_name(name) {
 		return nil, errors.New("Invalid Name")
 	}
@@ -69,58 +108,169 @@ func (n *Node) New(name string, def_val interface{}) (*Node, error) {
 		delimiter: n.delimiter,
 		parent:    n,
 		nodes:     make(map[string]*Node),
+		config:    n.config,
 	}
 
-	if def_val != nil {
-		new_node.def_val = new(string)
+	if !isNilDefault(def_val) {
+		str_val, err := defaultString(def_val)
+		if err != nil {
+			return nil, err
+		}
+		new_node.def_val = &str_val
+	}
 
-		var str_val string
-		switch t := def_val.(type) {
-		case string:
-			if val, ok := def_val.(string); ok {
-				str_val = val
-			} else {
-				return nil, errors.New("Unabled to assert type string on default value")
-			}
-		case []byte:
-			if val, ok := def_val.([]byte); ok {
-				str_val = string(val)
-			} else {
-				return nil, errors.New("Unabled to assert type []byte on default value")
-			}
-		case fmt.Stringer:
-			if val, ok := def_val.(fmt.Stringer); ok {
-				str_val = val.String()
-			} else {
-				return nil, errors.New("Unabled to assert type fmt.Stringer on default value")
-			}
-		case int:
-			if val, ok := def_val.(int); ok {
-				str_val = strconv.Itoa(val)
-			} else {
-				return nil, errors.New("Unabled to assert type int on default value")
-			}
-		case float64:
-			if val, ok := def_val.(float64); ok {
-				str_val = strconv.FormatFloat(val, 'f', -1, 64)
-			} else {
-				return nil, errors.New("Unabled to assert type float64 on default value")
+	for _, option := range options {
+		option(new_node)
+	}
+
+	n.nodes[name] = new_node
+	n.config.notify()
+	return n.nodes[name], nil
+}
+
+// Converts a default value as accepted by New to a string.
+func defaultString(def_val interface{}) (string, error) {
+	def_val, err := derefDefault(def_val)
+	if err != nil {
+		return "", err
+	}
+
+	var str_val string
+	switch t := def_val.(type) {
+	case string:
+		if val, ok := def_val.(string); ok {
+			str_val = val
+		} else {
+			return "", errors.New("Unabled to assert type string on default value")
+		}
+	case []byte:
+		if val, ok := def_val.([]byte); ok {
+			str_val = string(val)
+		} else {
+			return "", errors.New("Unabled to assert type []byte on default value")
+		}
+	case time.Duration:
+		if val, ok := def_val.(time.Duration); ok {
+			str_val = val.String()
+		} else {
+			return "", errors.New("Unabled to assert type time.Duration on default value")
+		}
+	case time.Time:
+		if val, ok := def_val.(time.Time); ok {
+			str_val = val.Format(time.RFC3339Nano)
+		} else {
+			return "", errors.New("Unabled to assert type time.Time on default value")
+		}
+	case fmt.Stringer:
+		if val, ok := def_val.(fmt.Stringer); ok {
+			str_val = val.String()
+		} else {
+			return "", errors.New("Unabled to assert type fmt.Stringer on default value")
+		}
+	case encoding.TextMarshaler:
+		if val, ok := def_val.(encoding.TextMarshaler); ok {
+			text, err := val.MarshalText()
+			if err != nil {
+				return "", err
 			}
-		case bool:
-			if val, ok := def_val.(bool); ok {
-				str_val = strconv.FormatBool(val)
-			} else {
-				return nil, errors.New("Unabled to assert type bool on default value")
+			str_val = string(text)
+		} else {
+			return "", errors.New("Unabled to assert type encoding.TextMarshaler on default value")
+		}
+	case int:
+		if val, ok := def_val.(int); ok {
+			str_val = strconv.Itoa(val)
+		} else {
+			return "", errors.New("Unabled to assert type int on default value")
+		}
+	case float64:
+		if val, ok := def_val.(float64); ok {
+			str_val = strconv.FormatFloat(val, 'f', -1, 64)
+		} else {
+			return "", errors.New("Unabled to assert type float64 on default value")
+		}
+	case bool:
+		if val, ok := def_val.(bool); ok {
+			str_val = strconv.FormatBool(val)
+		} else {
+			return "", errors.New("Unabled to assert type bool on default value")
+		}
+	case []string:
+		if val, ok := def_val.([]string); ok {
+			str_val = joinList(val, ListSeparator)
+		} else {
+			return "", errors.New("Unabled to assert type []string on default value")
+		}
+	case []int:
+		if val, ok := def_val.([]int); ok {
+			elems := make([]string, len(val))
+			for i, elem := range val {
+				elems[i] = strconv.Itoa(elem)
 			}
+			str_val = joinList(elems, ListSeparator)
+		} else {
+			return "", errors.New("Unabled to assert type []int on default value")
+		}
+	case map[string]string:
+		if val, ok := def_val.(map[string]string); ok {
+			str_val = joinMap(val, ListSeparator, MapSeparator)
+		} else {
+			return "", errors.New("Unabled to assert type map[string]string on default value")
+		}
+	default:
+		val := reflect.ValueOf(def_val)
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			str_val = strconv.FormatInt(val.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			str_val = strconv.FormatUint(val.Uint(), 10)
+		case reflect.Float32, reflect.Float64:
+			str_val = strconv.FormatFloat(val.Float(), 'f', -1, val.Type().Bits())
+		case reflect.Complex64, reflect.Complex128:
+			str_val = strconv.FormatComplex(val.Complex(), 'f', -1, val.Type().Bits())
 		default:
-			return nil, errors.New(fmt.Sprintf("Unexpected type %T", t))
+			return "", errors.New(fmt.Sprintf("Unexpected type %T", t))
 		}
+	}
+
+	return str_val, nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
 
-		*new_node.def_val = str_val
+// Returns the value def_val points to, following pointers until a value which isn't a pointer, so that for example a *time.Time is stored in the same way as a time.Time.
+// A pointer is kept if only the pointer, and not the value it points to, implements fmt.Stringer or encoding.TextMarshaler.
+func derefDefault(def_val interface{}) (interface{}, error) {
+	val := reflect.ValueOf(def_val)
+	if !val.IsValid() {
+		return def_val, nil
 	}
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil, errors.New(fmt.Sprintf("Unexpected nil %T", def_val))
+		}
+		elem := val.Elem()
+		if isTextType(val.Type()) && !isTextType(elem.Type()) {
+			break
+		}
+		val = elem
+	}
+	return val.Interface(), nil
+}
 
-	n.nodes[name] = new_node
-	return n.nodes[name], nil
+// Returns whether values of type t convert themselves to text.
+func isTextType(t reflect.Type) bool {
+	return t.Implements(stringerType) || t.Implements(textMarshalerType)
+}
+
+// Returns whether def_val is nil or a pointer which, directly or through other pointers, points to nil.
+// New treats such values as no default value.
+func isNilDefault(def_val interface{}) bool {
+	val := reflect.ValueOf(def_val)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	return !val.IsValid() || val.Kind() == reflect.Pointer
 }
 
 func valid_name(name string) bool {
@@ -193,12 +343,50 @@ func (n *Node) Nodes() []*Node {
 	return nodes
 }
 
+// Returns the child nodes of the node sorted by name.
+func (n *Node) children() []*Node {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	names := make([]string, 0, len(n.nodes))
+	for name := range n.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	children := make([]*Node, len(names))
+	for i, name := range names {
+		children[i] = n.nodes[name]
+	}
+	return children
+}
+
+// Returns the node and all of its child nodes recursively, sorted by path.
+func (n *Node) walk() []*Node {
+	nodes := []*Node{n}
+	for _, child := range n.children() {
+		nodes = append(nodes, child.walk()...)
+	}
+	return nodes
+}
+
+// Returns the default value of the node and whether the default value is not nil.
+func (n *Node) defaultValue() (string, bool) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	if n.def_val == nil {
+		return "", false
+	}
+	return *n.def_val, true
+}
+
 // Orphans the node as defined by path.
 // Sets the default value for the node to nil.
 func (n *Node) Delete(path ...string) error {
 	node := n.Node(path...)
 	if node == nil {
-		return errors.New("Does not exist")
+		return ErrNotExist
 	}
 
 	node_full_name := node.FullName()
@@ -219,6 +407,12 @@ func (n *Node) Delete(path ...string) error {
 	node.parent = nil
 	node.def_val = nil
 
+	node.tmpl_mutex.Lock()
+	node.tmpl = nil
+	node.tmpl_mutex.Unlock()
+
+	node.config.setOverride(node_full_name, nil)
+
 	return nil
 }
 
@@ -232,6 +426,18 @@ func (n *Node) Environment() []string {
 	return n.environmentOffset(0)
 }
 
+// Returns the root node of the tree the node is in.
+func (n *Node) root() *Node {
+	n.mutex.RLock()
+	parent := n.parent
+	n.mutex.RUnlock()
+
+	if parent == nil {
+		return n
+	}
+	return parent.root()
+}
+
 func (n *Node) path() []string {
 	n.mutex.RLock()
 	defer n.mutex.RUnlock()
@@ -0,0 +1,97 @@
+package constant
+
+import (
+	"sync"
+	"time"
+)
+
+// An Option configures a tree created by NewTree.
+type Option func(*treeConfig)
+
+// A NodeOption configures a node created by New.
+type NodeOption func(*Node)
+
+// Marks the node as required.
+// Validate reports required nodes whose effective value is an empty string.
+func Required() NodeOption {
+	return func(n *Node) {
+		n.required = true
+	}
+}
+
+// Marks the node as secret.
+// The value of a secret node, and of any node whose template references it, is redacted by String, Dump, WriteYAML and Explain (see Redacted).
+// Errors in the template of a secret node don't include the message from text/template, which can quote the template (see TemplateError).
+func Secret() NodeOption {
+	return func(n *Node) {
+		n.secret = true
+	}
+}
+
+// Configuration shared by every node in a tree.
+type treeConfig struct {
+	mutex            sync.RWMutex
+	sources          []Source
+	overrides        MapSource
+	file_indirection bool
+	empty_values     bool
+	watch_interval   time.Duration
+
+	changed_mutex sync.Mutex
+	changed       chan struct{}
+
+	// Held by Reloader.Reload while validating and swapping in new values.
+	reload_mutex sync.Mutex
+}
+
+// Sets the sources a tree looks up values from, in priority order.
+// The first source to return a value for a node is used instead of the node's default value.
+// The sources replace the default source, EnvSource.
+//
+// Runtime overrides (see Node.Set) take priority over every source unless Overrides is included in sources, in which case they are consulted at that position.
+//
+// For example the following tree reads values from the environment first and then from a map.
+//
+//	tree := constant.NewTree("MYAPP", "_", constant.WithSources(
+//		constant.EnvSource{},
+//		constant.MapSource{"MYAPP_DATABASE_HOST": "localhost"},
+//	))
+func WithSources(sources ...Source) Option {
+	return func(config *treeConfig) {
+		config.sources = append([]Source(nil), sources...)
+	}
+}
+
+// Enables reading values from files named by "<FullName>_FILE", as used by Docker and Kubernetes secrets.
+//
+// If a source has no value for a node but has a value for the node's full name followed by FileSuffix, that value is the name of a file whose contents are used as the node's value.
+// A single trailing newline is removed from the file's contents.
+// For example with MYAPP_DB_PASSWORD_FILE=/run/secrets/db set in the environment, the value of MYAPP_DB_PASSWORD is read from /run/secrets/db.
+// If the tree has a node with the full name <FullName>_FILE, such as MYAPP_LOG_FILE next to MYAPP_LOG, that name is only used as the value of that node.
+//
+// A file that can't be read is reported as a *SourceError rather than falling back to the node's default value.
+func WithFileIndirection() Option {
+	return func(config *treeConfig) {
+		config.file_indirection = true
+	}
+}
+
+// Treats an empty value returned by a source as set, like os.LookupEnv, instead of falling back to the next source or the node's default value.
+// For example with MYAPP_LOG_FILE= set in the environment, the value of MYAPP_LOG_FILE is an empty string even if its default value is not.
+//
+// Empty runtime overrides (see Node.Set) are also used.
+// An empty <FullName>_FILE value (see WithFileIndirection) still doesn't name a file.
+func WithEmptyValues() Option {
+	return func(config *treeConfig) {
+		config.empty_values = true
+	}
+}
+
+// Sets how often Watch checks for changes to values which the tree isn't notified about, such as environment variables.
+// Defaults to DefaultWatchInterval.
+// An interval of 0 or less turns checking off, so Watch only reports changes made through the tree, such as Set and Reloader.
+func WithWatchInterval(interval time.Duration) Option {
+	return func(config *treeConfig) {
+		config.watch_interval = interval
+	}
+}
@@ -0,0 +1,65 @@
+package constant
+
+// A placeholder for the tree's runtime overrides (see Node.Set) in the sources passed to WithSources.
+//
+// By default runtime overrides take priority over every source.
+// Including Overrides in WithSources gives them the priority of its position instead.
+// For example the following tree uses environment variables before runtime overrides, and runtime overrides before default values.
+//
+//	tree := constant.NewTree("MYAPP", "_", constant.WithSources(constant.EnvSource{}, constant.Overrides))
+var Overrides Source = &overrides{}
+
+type overrides struct{}
+
+func (*overrides) Lookup(path []string, fullName string) (string, bool, error) {
+	return "", false, nil
+}
+
+// Sets (or, if val is nil, removes) the runtime override for the node with the given full name.
+// The overrides are copied on write so that snapshots of the tree's sources don't change.
+func (c *treeConfig) setOverride(fullName string, val *string) {
+	c.mutex.Lock()
+	overrides := make(MapSource, len(c.overrides)+1)
+	for name, override := range c.overrides {
+		overrides[name] = override
+	}
+	if val == nil {
+		delete(overrides, fullName)
+	} else {
+		overrides[fullName] = *val
+	}
+	c.overrides = overrides
+	c.mutex.Unlock()
+
+	c.notify()
+}
+
+// Sets a runtime override for the value of the node as defined by path.
+//
+// The override is kept in memory, and is used instead of the node's default value and, unless the tree was created with Overrides at a lower priority (see Overrides), instead of the tree's sources.
+// val is converted to a string in the same way as New converts default values.
+// Like the values of sources, an override equal to an empty string is ignored unless the tree was created with WithEmptyValues.
+// If val is nil or a nil pointer, at any depth, the override is removed (see Unset).
+func (n *Node) Set(val interface{}, path ...string) error {
+	node := n.Node(path...)
+	if node == nil {
+		return ErrNotExist
+	}
+
+	if isNilDefault(val) {
+		node.config.setOverride(node.FullName(), nil)
+		return nil
+	}
+
+	str_val, err := defaultString(val)
+	if err != nil {
+		return err
+	}
+	node.config.setOverride(node.FullName(), &str_val)
+	return nil
+}
+
+// Removes the runtime override of the node as defined by path (see Set), so that its value comes from the tree's sources or default value again.
+func (n *Node) Unset(path ...string) error {
+	return n.Set(nil, path...)
+}
@@ -0,0 +1,220 @@
+package constant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// A FileSource looks up values parsed from a file by the node's full name.
+// The file is read when the source is created and again each time it is reloaded by a Reloader.
+type FileSource struct {
+	filename string
+	parse    func(io.Reader) (MapSource, error)
+
+	mutex    sync.RWMutex
+	values   MapSource
+	mod_time time.Time
+	size     int64
+}
+
+// Returns a source with the values parsed from the named file by parse.
+func NewFileSource(filename string, parse func(io.Reader) (MapSource, error)) (*FileSource, error) {
+	f := &FileSource{filename: filename, parse: parse}
+	values, info, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+
+	f.values = values
+	f.mod_time, f.size = info.ModTime(), info.Size()
+	return f, nil
+}
+
+// Returns a source with the values of the named .env file (see ParseDotenv).
+func NewDotenvSource(filename string) (*FileSource, error) {
+	return NewFileSource(filename, ParseDotenv)
+}
+
+// Returns the name of the file the source reads.
+func (f *FileSource) Filename() string {
+	return f.filename
+}
+
+func (f *FileSource) Lookup(path []string, fullName string) (string, bool, error) {
+	return f.snapshot().Lookup(path, fullName)
+}
+
+func (f *FileSource) snapshot() Source {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.values
+}
+
+// Reads and parses the file.
+func (f *FileSource) read() (MapSource, os.FileInfo, error) {
+	file, err := os.Open(f.filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values, err := f.parse(file)
+	if err != nil {
+		return nil, nil, &os.PathError{Op: "parse", Path: f.filename, Err: err}
+	}
+	return values, info, nil
+}
+
+// Returns whether the file has been modified since it was last read.
+func (f *FileSource) modified() bool {
+	info, err := os.Stat(f.filename)
+	if err != nil {
+		return true
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return !info.ModTime().Equal(f.mod_time) || info.Size() != f.size
+}
+
+/*
+A Reloader reloads file sources of a tree while a program is running.
+
+Files are reloaded when they are modified (checked every Interval) and when the process receives SIGHUP.
+Each reload reads and parses every file, then validates the tree with the new values (see Validate).
+Only if every file can be read and the tree is valid are the new values swapped in, all at once, so concurrent calls to Str never see some files reloaded and others not.
+Otherwise the previous values are kept and the error is passed to OnError.
+
+For example
+
+	env, err := constant.NewDotenvSource(".env")
+	if err != nil {
+		log.Fatal(err)
+	}
+	tree := constant.NewTree("MYAPP", "_", constant.WithSources(constant.EnvSource{}, env))
+	...
+	reloader := constant.NewReloader(tree, env)
+	reloader.OnError = func(err error) { log.Print(err) }
+	go reloader.Run(ctx)
+*/
+type Reloader struct {
+	// How often files are checked for modifications. Defaults to DefaultWatchInterval.
+	// If Interval is 0 or less files are only reloaded when the process receives SIGHUP.
+	Interval time.Duration
+	// Called with the error of every failed reload. Can be nil.
+	OnError func(error)
+
+	tree    *Node
+	sources []*FileSource
+}
+
+// Returns a reloader for sources, which must be sources of tree.
+// The whole tree is validated on each reload, so tree is normally the root of the tree.
+func NewReloader(tree *Node, sources ...*FileSource) *Reloader {
+	return &Reloader{
+		Interval: DefaultWatchInterval,
+		tree:     tree,
+		sources:  sources,
+	}
+}
+
+// Reloads every file straight away.
+// If a file can't be read or parsed, or the tree isn't valid with the new values, the previous values are kept and the error is returned.
+// Reloads of the same tree, by this or any other Reloader, run one at a time so that values are never swapped in after being validated against values another reload has since replaced.
+func (r *Reloader) Reload() error {
+	r.tree.config.reload_mutex.Lock()
+	defer r.tree.config.reload_mutex.Unlock()
+
+	type staged struct {
+		values MapSource
+		info   os.FileInfo
+	}
+
+	var errs []error
+	reloaded := make(map[*FileSource]staged)
+	for _, source := range r.sources {
+		values, info, err := source.read()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reloaded[source] = staged{values, info}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	values := make(map[*FileSource]MapSource, len(reloaded))
+	for source, staged := range reloaded {
+		values[source] = staged.values
+	}
+	if err := r.tree.validate(r.tree.config.stagedSnapshot(values)); err != nil {
+		return err
+	}
+
+	// Snapshots are taken while holding a read lock, so no snapshot can see some sources swapped and others not.
+	r.tree.config.mutex.Lock()
+	for source, staged := range reloaded {
+		source.mutex.Lock()
+		source.values = staged.values
+		source.mod_time, source.size = staged.info.ModTime(), staged.info.Size()
+		source.mutex.Unlock()
+	}
+	r.tree.config.mutex.Unlock()
+
+	r.tree.config.notify()
+	return nil
+}
+
+// Reloads files when they are modified or the process receives SIGHUP, until ctx is done.
+func (r *Reloader) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+	if r.Interval > 0 {
+		ticker := time.NewTicker(r.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		case <-tick:
+			if !r.modified() {
+				continue
+			}
+		}
+
+		if err := r.Reload(); err != nil && r.OnError != nil {
+			r.OnError(err)
+		}
+	}
+}
+
+// Returns whether any of the files have been modified since they were last read.
+func (r *Reloader) modified() bool {
+	for _, source := range r.sources {
+		if source.modified() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package constant_test
+
+import (
+	"context"
+	"errors"
+	"github.com/JamesStewy/constant"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(filename, []byte("reload_HOST=first\nreload_PORT=1\n"), 0600)
+
+	env, err := constant.NewDotenvSource(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reload_tree := constant.NewTree("reload", "_", constant.WithSources(env), constant.WithWatchInterval(10*time.Millisecond))
+	reload_tree.New("HOST", "localhost")
+	reload_tree.New("PORT", 3306, constant.Validators(constant.IntRange(1, 65535)))
+	reload_tree.New("ADDRESS", `{{ const "HOST" }}:{{ const "PORT" }}`)
+
+	if str := reload_tree.Str("ADDRESS"); str != "first:1" {
+		t.Error(
+			"For", "ADDRESS",
+			"expected", "first:1",
+			"got", str,
+		)
+	}
+
+	reloader := constant.NewReloader(reload_tree, env)
+	reloader.Interval = 10 * time.Millisecond
+	errs := make(chan error, 10)
+	reloader.OnError = func(err error) { errs <- err }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	poll_ctx, poll_cancel := context.WithCancel(ctx)
+	go reloader.Run(poll_ctx)
+
+	// Only reloads on SIGHUP
+	hangup_reloader := constant.NewReloader(reload_tree, env)
+	hangup_reloader.Interval = 0
+	go hangup_reloader.Run(ctx)
+
+	changes, _ := reload_tree.Watch(ctx, "ADDRESS")
+
+	// Modified file is reloaded
+	os.WriteFile(filename, []byte("reload_HOST=second\nreload_PORT=22\n"), 0600)
+	expectChange(t, changes, "first:1", "second:22")
+
+	// Invalid values are rejected and the previous values are kept
+	os.WriteFile(filename, []byte("reload_HOST=third\nreload_PORT=70000\n"), 0600)
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("expected validation error got nil")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected validation error got none")
+	}
+	if str := reload_tree.Str("ADDRESS"); str != "second:22" {
+		t.Error(
+			"For", "ADDRESS after invalid reload",
+			"expected", "second:22",
+			"got", str,
+		)
+	}
+
+	// Unparsable files are rejected
+	os.WriteFile(filename, []byte("reload_HOST=\"unterminated\n"), 0600)
+	if err := reloader.Reload(); err == nil {
+		t.Error("expected parse error got nil")
+	}
+
+	// SIGHUP reloads straight away
+	poll_cancel()
+	os.WriteFile(filename, []byte("reload_HOST=fourth\nreload_PORT=4\n"), 0600)
+	process, _ := os.FindProcess(os.Getpid())
+	process.Signal(syscall.SIGHUP)
+	expectChange(t, changes, "second:22", "fourth:4")
+}
+
+func TestReloadShadowed(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(filename, []byte("reload_shadowed_PORT=1\n"), 0600)
+
+	file_source, err := constant.NewDotenvSource(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shadowed_tree := constant.NewTree("reload_shadowed", "_", constant.WithSources(constant.EnvSource{}, file_source))
+	shadowed_tree.New("PORT", 3306, constant.Validators(constant.IntRange(1, 65535)))
+
+	os.Setenv("reload_shadowed_PORT", "5")
+	defer os.Unsetenv("reload_shadowed_PORT")
+
+	// The invalid file value is hidden by the environment, so the tree is still valid
+	os.WriteFile(filename, []byte("reload_shadowed_PORT=70000\n"), 0600)
+	if err := constant.NewReloader(shadowed_tree, file_source).Reload(); err != nil {
+		t.Error("expected no error got", err)
+	}
+	if str := shadowed_tree.Str("PORT"); str != "5" {
+		t.Error(
+			"For", "PORT after reload",
+			"expected", "5",
+			"got", str,
+		)
+	}
+}
+
+func TestReloadConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	min_file, max_file := filepath.Join(dir, "min.env"), filepath.Join(dir, "max.env")
+	os.WriteFile(min_file, []byte("reload_concurrent_MIN=1\n"), 0600)
+	os.WriteFile(max_file, []byte("reload_concurrent_MAX=10\n"), 0600)
+
+	min_source, _ := constant.NewDotenvSource(min_file)
+	max_source, _ := constant.NewDotenvSource(max_file)
+	concurrent_tree := constant.NewTree("reload_concurrent", "_", constant.WithSources(min_source, max_source))
+
+	// Holds up the first reload while it validates MIN=5, giving the second reload a chance to run in between
+	validating, max_done := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	concurrent_tree.New("MIN", 0, constant.Validators(func(val string) error {
+		if val == "5" {
+			once.Do(func() {
+				close(validating)
+				select {
+				case <-max_done:
+				case <-time.After(100 * time.Millisecond):
+				}
+			})
+		}
+		return nil
+	}))
+	concurrent_tree.New("MAX", 0)
+	concurrent_tree.Constrain(func(s *constant.Scope) error {
+		min, _ := s.Int("MIN")
+		max, _ := s.Int("MAX")
+		if min > max {
+			return errors.New("MIN must not be greater than MAX")
+		}
+		return nil
+	})
+
+	// Each change is valid on its own, but not together
+	os.WriteFile(min_file, []byte("reload_concurrent_MIN=5\n"), 0600)
+	os.WriteFile(max_file, []byte("reload_concurrent_MAX=3\n"), 0600)
+
+	min_err := make(chan error)
+	go func() { min_err <- constant.NewReloader(concurrent_tree, min_source).Reload() }()
+	<-validating
+	max_err := constant.NewReloader(concurrent_tree, max_source).Reload()
+	close(max_done)
+
+	if err := <-min_err; err != nil {
+		t.Error("For", "MIN", "expected no error got", err)
+	}
+	if max_err == nil {
+		t.Error("For", "MAX", "expected constraint error got", nil)
+	}
+	if err := concurrent_tree.Validate(); err != nil {
+		t.Error("expected valid tree got", err, concurrent_tree.Dump())
+	}
+}
@@ -0,0 +1,70 @@
+package constant
+
+import (
+	"strconv"
+)
+
+// Replaces the value of secret nodes in String, Dump and WriteYAML.
+const Redacted = "[REDACTED]"
+
+// Marks the node as defined by path as secret (see Secret).
+func (n *Node) Conceal(path ...string) error {
+	node := n.Node(path...)
+	if node == nil {
+		return ErrNotExist
+	}
+
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	node.secret = true
+	return nil
+}
+
+// Returns whether the node as defined by path is secret.
+func (n *Node) IsSecret(path ...string) bool {
+	node := n.Node(path...)
+	if node == nil {
+		return false
+	}
+
+	node.mutex.RLock()
+	defer node.mutex.RUnlock()
+
+	return node.secret
+}
+
+// Returns Redacted if the node is secret and val is not empty, otherwise val.
+func (n *Node) redact(val string) string {
+	if val != "" && n.IsSecret() {
+		return Redacted
+	}
+	return val
+}
+
+// Evaluates the node like Str, except that a non empty value is replaced with Redacted if the node is secret or its template references a secret node, even through other nodes.
+func (n *Node) redacted() (string, error) {
+	trace := &Explanation{}
+	_, err := n.eval(nil, nil, trace)
+	return trace.Value, err
+}
+
+// Implements fmt.GoStringer so that formatting a node with %#v shows its full name and redacted value rather than its internal fields.
+func (n *Node) GoString() string {
+	return "constant.Node{" + n.FullName() + "=" + strconv.Quote(n.String()) + "}"
+}
+
+// Returns a sorted slice of "FULLNAME=value" pairs for itself and all child nodes in the node that have non nil default values, like Environment.
+// Values are evaluated as by Str, except that the values of secret nodes, and of nodes whose templates reference secret nodes, are replaced with Redacted.
+func (n *Node) Dump() []string {
+	env := n.Environment()
+	nodes := make(map[string]*Node)
+	for _, node := range n.Nodes() {
+		nodes[node.FullName()] = node
+	}
+
+	for i, name := range env {
+		env[i] = name + "=" + nodes[name].String()
+	}
+	return env
+}
@@ -0,0 +1,170 @@
+package constant_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/JamesStewy/constant"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	secret_tree := constant.NewTree("secret", "_")
+	secret_tree.New("USER", "admin")
+	secret_tree.New("PASSWORD", "hunter2", constant.Secret())
+	secret_tree.New("TOKEN", "abc123")
+	secret_tree.Conceal("TOKEN")
+	secret_tree.New("EMPTY", "", constant.Secret())
+	secret_tree.New("DSN", `{{ const "USER" }}:{{ const "PASSWORD" }}@db`, constant.Secret())
+
+	if str := secret_tree.Str("PASSWORD"); str != "hunter2" {
+		t.Error(
+			"For Str", "PASSWORD",
+			"expected", "hunter2",
+			"got", str,
+		)
+	}
+	if str := secret_tree.Str("DSN"); str != "admin:hunter2@db" {
+		t.Error(
+			"For Str", "DSN",
+			"expected", "admin:hunter2@db",
+			"got", str,
+		)
+	}
+
+	password := secret_tree.Node("PASSWORD")
+	for _, format := range []string{"%v", "%s", "%+v", "%q", "%#v"} {
+		if str := fmt.Sprintf(format, password); strings.Contains(str, "hunter2") || !strings.Contains(str, constant.Redacted) {
+			t.Error(
+				"For format", format,
+				"expected", constant.Redacted,
+				"got", str,
+			)
+		}
+	}
+
+	exp := []string{
+		"secret_DSN=" + constant.Redacted,
+		"secret_EMPTY=",
+		"secret_PASSWORD=" + constant.Redacted,
+		"secret_TOKEN=" + constant.Redacted,
+		"secret_USER=admin",
+	}
+	if dump := secret_tree.Dump(); !reflect.DeepEqual(dump, exp) {
+		t.Error(
+			"expected", exp,
+			"got", dump,
+		)
+	}
+
+	var effective, defaults bytes.Buffer
+	secret_tree.WriteYAML(&effective)
+	secret_tree.WriteDefaultsYAML(&defaults)
+	for _, out := range []string{effective.String(), defaults.String()} {
+		if strings.Contains(out, "hunter2") || strings.Contains(out, "abc123") {
+			t.Error(
+				"expected redacted YAML",
+				"got", out,
+			)
+		}
+	}
+}
+
+func TestSecretReference(t *testing.T) {
+	secret_tree := constant.NewTree("S", "_")
+	secret_tree.New("PW", "hunter2", constant.Secret())
+	secret_tree.New("HOST", "h")
+	// Neither DSN nor URL is secret, but both are built from PW
+	secret_tree.New("DSN", `u:{{ const "PW" }}@{{ const "HOST" }}`)
+	secret_tree.New("URL", `db://{{ const "DSN" }}`)
+	secret_tree.New("ADDRESS", `{{ const "HOST" }}:5432`)
+	secret_tree.New("ISSET", `{{ isset "PW" }}`)
+
+	if str := secret_tree.Str("URL"); str != "db://u:hunter2@h" {
+		t.Error(
+			"For Str", "URL",
+			"expected", "db://u:hunter2@h",
+			"got", str,
+		)
+	}
+
+	exp := []string{
+		"S_ADDRESS=h:5432",
+		"S_DSN=" + constant.Redacted,
+		"S_HOST=h",
+		"S_ISSET=true",
+		"S_PW=" + constant.Redacted,
+		"S_URL=" + constant.Redacted,
+	}
+	if dump := secret_tree.Dump(); !reflect.DeepEqual(dump, exp) {
+		t.Error(
+			"expected", exp,
+			"got", dump,
+		)
+	}
+
+	if str := fmt.Sprint(secret_tree.Node("DSN")); str != constant.Redacted {
+		t.Error(
+			"For String", "DSN",
+			"expected", constant.Redacted,
+			"got", str,
+		)
+	}
+
+	var effective bytes.Buffer
+	secret_tree.WriteYAML(&effective)
+	if out := effective.String(); strings.Contains(out, "hunter2") || !strings.Contains(out, "ADDRESS: h:5432") {
+		t.Error(
+			"expected redacted YAML",
+			"got", out,
+		)
+	}
+
+	if e, _ := secret_tree.Explain("URL"); e.Value != constant.Redacted || strings.Contains(e.String(), "hunter2") {
+		t.Error(
+			"For Explain", "URL",
+			"expected", constant.Redacted,
+			"got", e,
+		)
+	}
+}
+
+func TestSecretTemplateError(t *testing.T) {
+	secret_tree := constant.NewTree("secret", "_")
+	secret_tree.New("PASSWORD", "default", constant.Secret())
+	secret_tree.New("USER", "{{ typo }}")
+
+	os.Setenv("secret_PASSWORD", "hunter2{{s3cr3t}}")
+	defer os.Unsetenv("secret_PASSWORD")
+
+	// The position of the error is kept but not the message from text/template, which quotes the template
+	_, err := secret_tree.Eval("PASSWORD")
+	var template_err *constant.TemplateError
+	if !errors.As(err, &template_err) || template_err.Op != "parse" || template_err.Line != 1 {
+		t.Error("For", "PASSWORD", "expected parse TemplateError on line 1", "got", err)
+	}
+
+	validate_err := secret_tree.Validate()
+	var yaml bytes.Buffer
+	yaml_err := secret_tree.WriteYAML(&yaml)
+	explanation, explain_err := secret_tree.Explain("PASSWORD")
+	outputs := map[string]string{
+		"Eval":      fmt.Sprint(err),
+		"Validate":  fmt.Sprint(validate_err),
+		"WriteYAML": fmt.Sprint(yaml_err) + yaml.String(),
+		"Explain":   fmt.Sprint(explain_err) + explanation.String(),
+	}
+	for name, out := range outputs {
+		if strings.Contains(out, "s3cr3t") || strings.Contains(out, "hunter2") || !strings.Contains(out, constant.Redacted) {
+			t.Error("For", name, "expected redacted error", "got", out)
+		}
+	}
+
+	// Errors in the templates of other nodes are still reported in full
+	if _, err := secret_tree.Eval("USER"); err == nil || !strings.Contains(err.Error(), `function "typo" not defined`) {
+		t.Error("For", "USER", "expected", `function "typo" not defined`, "got", err)
+	}
+}
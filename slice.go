@@ -0,0 +1,181 @@
+package constant
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// An ElementError is returned when an element of a list value can't be read or converted.
+type ElementError struct {
+	// Index of the element in the list, starting at 0.
+	Index int
+	Err   error
+}
+
+func (e *ElementError) Error() string {
+	return "Element " + strconv.Itoa(e.Index) + ": " + e.Err.Error()
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// The separator New uses to store slice default values.
+const ListSeparator = ","
+
+/*
+Splits str into the elements of a list separated by sep.
+
+Whitespace around each element is removed.
+An element can be quoted to keep whitespace or to contain sep: double quoted elements follow the Go syntax for strings (see strconv.Unquote) and single quoted elements are taken literally.
+For example with sep "," the value
+
+	a:9092, "b:9092,c:9092" , ' d '
+
+has the elements "a:9092", "b:9092,c:9092" and " d ".
+
+An empty value is an empty list.
+*/
+func splitList(str, sep string) ([]string, error) {
+	if sep == "" {
+		return nil, errors.New("Empty separator")
+	}
+	if strings.TrimSpace(str) == "" {
+		return nil, nil
+	}
+
+	var elems []string
+	for i := 0; ; i++ {
+		str = strings.TrimLeft(str, " \t\r\n")
+
+		var elem string
+		if str != "" && (str[0] == '"' || str[0] == '\'') {
+			end := quotedEnd(str)
+			if end < 0 {
+				return nil, &ElementError{i, errors.New("Unterminated quote")}
+			}
+			elem = str[1:end]
+			if str[0] == '"' {
+				var err error
+				if elem, err = strconv.Unquote(str[:end+1]); err != nil {
+					return nil, &ElementError{i, err}
+				}
+			}
+			str = strings.TrimLeft(str[end+1:], " \t\r\n")
+			if str != "" && !strings.HasPrefix(str, sep) {
+				return nil, &ElementError{i, errors.New("Unexpected text after quote")}
+			}
+		} else {
+			end := strings.Index(str, sep)
+			if end < 0 {
+				end = len(str)
+			}
+			elem = strings.TrimSpace(str[:end])
+			str = str[end:]
+		}
+
+		elems = append(elems, elem)
+		if str == "" {
+			return elems, nil
+		}
+		str = str[len(sep):]
+	}
+}
+
+// Returns the index of the quote that closes the quoted string at the start of str, or -1 if it isn't closed.
+func quotedEnd(str string) int {
+	quote := str[0]
+	for i := 1; i < len(str); i++ {
+		switch {
+		case str[i] == '\\' && quote == '"':
+			i++
+		case str[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// Joins elems into a list separated by sep that splitList reads back, quoting elements where needed.
+func joinList(elems []string, sep string) string {
+	quoted := make([]string, len(elems))
+	for i, elem := range elems {
+		quoted[i] = elem
+		if elem != strings.TrimSpace(elem) || strings.Contains(elem, sep) || strings.HasPrefix(elem, `"`) || strings.HasPrefix(elem, "'") || len(elems) == 1 && elem == "" {
+			quoted[i] = strconv.Quote(elem)
+		}
+	}
+	return strings.Join(quoted, sep)
+}
+
+// Returns the value of n.Str(path...) as a list of strings separated by sep.
+//
+// Whitespace around each element is removed and elements can be quoted to contain sep, for example `a, "b,c"` is the list "a" and "b,c" with sep ",".
+// An empty value is an empty list.
+// If an element can't be read an *ElementError is returned.
+// If the node can't be evaluated the error from n.Eval(path...) is returned.
+func (n *Node) StrSlice(sep string, path ...string) (val []string, err error) {
+	str, err := n.Eval(path...)
+	if err != nil {
+		return
+	}
+	val, err = splitList(str, sep)
+	return
+}
+
+// Run n.StrSlice(sep, path...) but ignore errors
+func (n *Node) StrSliceI(sep string, path ...string) (val []string) {
+	val, _ = n.StrSlice(sep, path...)
+	return
+}
+
+// Returns the value of n.StrSlice(sep, path...) as a list of integers.
+//
+// Each element follows convention of strconv.Atoi (https://golang.org/pkg/strconv/#Atoi).
+// An element that can't be converted is reported as an *ElementError with the element's index.
+func (n *Node) IntSlice(sep string, path ...string) ([]int, error) {
+	elems, err := n.StrSlice(sep, path...)
+	if err != nil {
+		return nil, err
+	}
+
+	val := make([]int, len(elems))
+	for i, elem := range elems {
+		if val[i], err = strconv.Atoi(elem); err != nil {
+			return nil, &ElementError{i, err}
+		}
+	}
+	return val, nil
+}
+
+// Run n.IntSlice(sep, path...) but ignore errors
+func (n *Node) IntSliceI(sep string, path ...string) (val []int) {
+	val, _ = n.IntSlice(sep, path...)
+	return
+}
+
+// Returns the value of n.StrSlice(sep, path...) as a list of float64s.
+//
+// Each element follows convention of strconv.ParseFloat (https://golang.org/pkg/strconv/#ParseFloat).
+// An element that can't be converted is reported as an *ElementError with the element's index.
+func (n *Node) FloatSlice(sep string, bitSize int, path ...string) ([]float64, error) {
+	elems, err := n.StrSlice(sep, path...)
+	if err != nil {
+		return nil, err
+	}
+
+	val := make([]float64, len(elems))
+	for i, elem := range elems {
+		if val[i], err = strconv.ParseFloat(elem, bitSize); err != nil {
+			return nil, &ElementError{i, err}
+		}
+	}
+	return val, nil
+}
+
+// Run n.FloatSlice(sep, bitSize, path...) but ignore errors
+func (n *Node) FloatSliceI(sep string, bitSize int, path ...string) (val []float64) {
+	val, _ = n.FloatSlice(sep, bitSize, path...)
+	return
+}
@@ -0,0 +1,83 @@
+package constant_test
+
+import (
+	"errors"
+	"github.com/JamesStewy/constant"
+	"reflect"
+	"testing"
+)
+
+func TestStrSlice(t *testing.T) {
+	slice_tree := constant.NewTree("slice", "_")
+	slice_tree.New("BROKERS", "a:9092, b:9092 ,c:9092")
+	slice_tree.New("QUOTED", `"a,b", ' c ' , "d\"e",f`)
+	slice_tree.New("PIPES", "a | b|c")
+	slice_tree.New("EMPTY", "")
+	slice_tree.New("BLANK", "a,,b")
+	slice_tree.New("DEFAULT", []string{"a", "b,c", " d", `"e`, ""})
+	slice_tree.New("UNTERMINATED", `a, "b`)
+	slice_tree.New("TRAILING", `a, "b" c`)
+
+	results := []struct {
+		name string
+		sep  string
+		val  []string
+		err  bool
+	}{
+		{"BROKERS", ",", []string{"a:9092", "b:9092", "c:9092"}, false},
+		{"QUOTED", ",", []string{"a,b", " c ", `d"e`, "f"}, false},
+		{"PIPES", "|", []string{"a", "b", "c"}, false},
+		{"EMPTY", ",", nil, false},
+		{"BLANK", ",", []string{"a", "", "b"}, false},
+		{"DEFAULT", ",", []string{"a", "b,c", " d", `"e`, ""}, false},
+		{"UNTERMINATED", ",", nil, true},
+		{"TRAILING", ",", nil, true},
+		{"BROKERS", "", nil, true},
+		{"MISSING", ",", nil, true},
+	}
+
+	for _, r := range results {
+		val, err := slice_tree.StrSlice(r.sep, r.name)
+		if !reflect.DeepEqual(val, r.val) || (err != nil) != r.err {
+			t.Error(
+				"For", r.name,
+				"expected", r.val, r.err,
+				"got (", val, err, ")",
+			)
+		}
+	}
+
+	var elem_err *constant.ElementError
+	if _, err := slice_tree.StrSlice(",", "TRAILING"); !errors.As(err, &elem_err) || elem_err.Index != 1 {
+		t.Error("For", "TRAILING", "expected *ElementError at index 1 got", err)
+	}
+}
+
+func TestIntSlice(t *testing.T) {
+	slice_tree := constant.NewTree("slice", "_")
+	slice_tree.New("PORTS", []int{80, 443, -1})
+	slice_tree.New("INVALID", "1, 2, three")
+	slice_tree.New("RATIOS", "0.5; 0.25")
+
+	if val, err := slice_tree.IntSlice(",", "PORTS"); !reflect.DeepEqual(val, []int{80, 443, -1}) || err != nil {
+		t.Error("For", "PORTS", "expected", []int{80, 443, -1}, "got (", val, err, ")")
+	}
+	if str := slice_tree.Str("PORTS"); str != "80,443,-1" {
+		t.Error("For", "PORTS", "expected", "80,443,-1", "got", str)
+	}
+
+	var elem_err *constant.ElementError
+	if val, err := slice_tree.IntSlice(",", "INVALID"); !errors.As(err, &elem_err) || elem_err.Index != 2 {
+		t.Error("For", "INVALID", "expected *ElementError at index 2 got (", val, err, ")")
+	}
+	if val := slice_tree.IntSliceI(",", "INVALID"); val != nil {
+		t.Error("For", "INVALID", "expected", nil, "got", val)
+	}
+
+	if val, err := slice_tree.FloatSlice(";", 64, "RATIOS"); !reflect.DeepEqual(val, []float64{0.5, 0.25}) || err != nil {
+		t.Error("For", "RATIOS", "expected", []float64{0.5, 0.25}, "got (", val, err, ")")
+	}
+	if _, err := slice_tree.FloatSlice(",", 64, "RATIOS"); !errors.As(err, &elem_err) || elem_err.Index != 0 {
+		t.Error("For", "RATIOS", "expected *ElementError at index 0 got", err)
+	}
+}
@@ -0,0 +1,154 @@
+package constant
+
+import (
+	"os"
+)
+
+// A Source provides values for nodes which are used instead of the nodes' default values.
+type Source interface {
+	// Returns the value for a node.
+	// path is the path of the node relative to the root of the tree and fullName is the node's full name (see Node.FullName).
+	// ok is false if the source has no value for the node.
+	Lookup(path []string, fullName string) (value string, ok bool, err error)
+}
+
+// A SourceError is returned when a source fails to look up the value of a node.
+type SourceError struct {
+	// Full name of the node being looked up.
+	Name string
+	Err  error
+}
+
+func (e *SourceError) Error() string {
+	return "Unable to look up " + e.Name + ": " + e.Err.Error()
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// EnvSource looks up values in the process environment by the node's full name.
+// It is the default source of a tree.
+type EnvSource struct{}
+
+func (EnvSource) Lookup(path []string, fullName string) (string, bool, error) {
+	val, ok := os.LookupEnv(fullName)
+	return val, ok, nil
+}
+
+// MapSource looks up values in a map by the node's full name.
+type MapSource map[string]string
+
+func (m MapSource) Lookup(path []string, fullName string) (string, bool, error) {
+	val, ok := m[fullName]
+	return val, ok, nil
+}
+
+// A source whose values can change, such as a FileSource.
+type snapshotter interface {
+	// Returns a source with the current values which doesn't change.
+	snapshot() Source
+}
+
+// The values of a source at the time a snapshot of a tree's sources was taken.
+type sourceSnapshot struct {
+	Source
+	// The source the values were taken from.
+	origin Source
+}
+
+// Returns the source the values of source were taken from.
+func origin(source Source) Source {
+	if snapshot, ok := source.(sourceSnapshot); ok {
+		return snapshot.origin
+	}
+	return source
+}
+
+// Returns the tree's sources, with sources whose values can change replaced by their current values.
+// All of the values in a snapshot were current at the same time (see Reloader).
+func (c *treeConfig) snapshot() []Source {
+	return c.stagedSnapshot(nil)
+}
+
+// Returns a snapshot of the tree's sources (see snapshot) in which each FileSource in staged has the staged values instead of its current values.
+func (c *treeConfig) stagedSnapshot(staged map[*FileSource]MapSource) []Source {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	sources := make([]Source, 0, len(c.sources)+1)
+	has_overrides := false
+	for _, source := range c.sources {
+		if f, ok := source.(*FileSource); ok && staged[f] != nil {
+			source = sourceSnapshot{staged[f], source}
+		} else if source == Overrides {
+			source = sourceSnapshot{c.overrides, Overrides}
+			has_overrides = true
+		} else if s, ok := source.(snapshotter); ok {
+			source = sourceSnapshot{s.snapshot(), source}
+		}
+		sources = append(sources, source)
+	}
+
+	if !has_overrides {
+		sources = append([]Source{sourceSnapshot{c.overrides, Overrides}}, sources...)
+	}
+	return sources
+}
+
+// The result of looking up the value of a node in a tree's sources.
+type lookupResult struct {
+	val string
+	// The source the value came from.
+	source Source
+	// The file the value was read from if the source named a file (see WithFileIndirection).
+	file string
+}
+
+// Returns the value of the node from the first of sources which has a non empty value for it, or any value if empty values are enabled (see WithEmptyValues).
+// If file indirection is enabled (see WithFileIndirection) each source is also checked for fullName + "_FILE", unless the tree has a node with that full name.
+func (n *Node) lookup(fullName string, sources []Source) (lookupResult, bool, error) {
+	path := n.path()[1:]
+	file_indirection := n.config.file_indirection && !n.hasFileNode(fullName)
+	for _, source := range sources {
+		val, ok, err := source.Lookup(path, fullName)
+		if err != nil {
+			return lookupResult{}, false, &SourceError{fullName, err}
+		}
+		if ok && (val != "" || n.config.empty_values) {
+			return lookupResult{val, origin(source), ""}, true, nil
+		}
+
+		if !file_indirection {
+			continue
+		}
+
+		file_path := path
+		if len(path) > 0 {
+			file_path = append(path[:len(path)-1:len(path)-1], path[len(path)-1]+FileSuffix)
+		}
+		filename, ok, err := source.Lookup(file_path, fullName+FileSuffix)
+		if err != nil {
+			return lookupResult{}, false, &SourceError{fullName, err}
+		}
+		if ok && filename != "" {
+			val, err := readValueFile(filename)
+			if err != nil {
+				return lookupResult{}, false, &SourceError{fullName, err}
+			}
+			return lookupResult{val, origin(source), filename}, true, nil
+		}
+	}
+	return lookupResult{}, false, nil
+}
+
+// Returns whether the tree n is in has a node whose full name is fullName followed by FileSuffix.
+// That node's value is then its own value rather than the name of a file holding the value of n.
+func (n *Node) hasFileNode(fullName string) bool {
+	for _, node := range n.root().walk() {
+		if node.FullName() == fullName+FileSuffix {
+			return true
+		}
+	}
+	return false
+}
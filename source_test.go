@@ -0,0 +1,168 @@
+package constant_test
+
+import (
+	"errors"
+	"github.com/JamesStewy/constant"
+	"os"
+	"testing"
+)
+
+type failingSource struct{}
+
+func (failingSource) Lookup(path []string, fullName string) (string, bool, error) {
+	return "", false, errors.New("failing source")
+}
+
+func TestSources(t *testing.T) {
+	source_tree := constant.NewTree("source", "_", constant.WithSources(
+		constant.MapSource{"source_first": "first map", "source_empty": ""},
+		constant.EnvSource{},
+		constant.MapSource{"source_first": "second map", "source_second": "second map", "source_empty": "second map"},
+	))
+	source_tree.New("first", "default")
+	source_tree.New("second", "default")
+	source_tree.New("env", "default")
+	source_tree.New("empty", "default")
+	source_tree.New("none", "default")
+
+	os.Setenv("source_env", "env")
+	os.Setenv("source_second", "env")
+	defer os.Unsetenv("source_env")
+	defer os.Unsetenv("source_second")
+
+	results := map[string]string{
+		"first":  "first map",
+		"second": "env",
+		"env":    "env",
+		"empty":  "second map",
+		"none":   "default",
+	}
+
+	for name, exp := range results {
+		if str := source_tree.Str(name); str != exp {
+			t.Error(
+				"For", name,
+				"expected", exp,
+				"got", str,
+			)
+		}
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	source_tree := constant.NewTree("source", "_", constant.WithSources(failingSource{}))
+	source_tree.New("value", "default")
+
+	var source_err *constant.SourceError
+	if _, err := source_tree.Eval("value"); !errors.As(err, &source_err) || source_err.Name != "source_value" {
+		t.Error(
+			"For", "value",
+			"expected SourceError",
+			"got", err,
+		)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	first_tree := constant.NewTree("override", "_")
+	last_tree := constant.NewTree("override", "_", constant.WithSources(constant.EnvSource{}, constant.Overrides))
+
+	os.Setenv("override_ENV", "env")
+	defer os.Unsetenv("override_ENV")
+
+	for _, override_tree := range []*constant.Node{first_tree, last_tree} {
+		override_tree.New("ENV", "default")
+		override_tree.New("PORT", 3306)
+		override_tree.New("ADDRESS", `{{ const "ENV" }}:{{ const "PORT" }}`)
+
+		if err := override_tree.Set(8080, "PORT"); err != nil {
+			t.Fatal(err)
+		}
+		override_tree.Set("override", "ENV")
+	}
+
+	results := []struct {
+		tree *constant.Node
+		path string
+		exp  string
+	}{
+		{first_tree, "ENV", "override"},
+		{first_tree, "PORT", "8080"},
+		{first_tree, "ADDRESS", "override:8080"},
+		{last_tree, "ENV", "env"},
+		{last_tree, "PORT", "8080"},
+		{last_tree, "ADDRESS", "env:8080"},
+	}
+	for _, test := range results {
+		if str := test.tree.Str(test.path); str != test.exp {
+			t.Error(
+				"For", test.path,
+				"expected", test.exp,
+				"got", str,
+			)
+		}
+	}
+
+	first_tree.Unset("ENV")
+	first_tree.Set(nil, "PORT")
+	if str := first_tree.Str("ADDRESS"); str != "env:3306" {
+		t.Error(
+			"For", "ADDRESS after Unset",
+			"expected", "env:3306",
+			"got", str,
+		)
+	}
+
+	if err := first_tree.Set("value", "DOESNTEXIST"); err != constant.ErrNotExist {
+		t.Error(
+			"For", "DOESNTEXIST",
+			"expected", constant.ErrNotExist,
+			"got", err,
+		)
+	}
+	if err := first_tree.Set(struct{}{}, "PORT"); err == nil {
+		t.Error(
+			"For", "unsupported type",
+			"expected error",
+			"got", nil,
+		)
+	}
+}
+
+func TestEmptyValues(t *testing.T) {
+	empty_tree := constant.NewTree("empty", "_", constant.WithEmptyValues())
+	empty_tree.New("FILE", "stdout")
+	empty_tree.New("LEVEL", "5")
+	empty_tree.New("NONE", nil)
+	empty_tree.New("USER", "admin")
+
+	os.Setenv("empty_FILE", "")
+	defer os.Unsetenv("empty_FILE")
+	empty_tree.Set("", "USER")
+
+	type result struct {
+		val string
+		ok  bool
+	}
+	results := map[string]result{
+		"FILE":    {"", true},
+		"LEVEL":   {"5", true},
+		"NONE":    {"", false},
+		"USER":    {"", true},
+		"MISSING": {"", false},
+	}
+
+	for name, expected := range results {
+		val, ok := empty_tree.Lookup(name)
+		if got := (result{val, ok}); got != expected {
+			t.Error("For", name, "expected", expected, "got", got)
+		}
+	}
+
+	// Without the option empty values fall back to the default value
+	default_tree := constant.NewTree("empty", "_")
+	default_tree.New("FILE", "stdout")
+	if val, ok := default_tree.Lookup("FILE"); val != "stdout" || !ok {
+		t.Error("For", "FILE", "expected", "stdout", true, "got", val, ok)
+	}
+}
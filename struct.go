@@ -0,0 +1,291 @@
+package constant
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A BindError is returned by Bind for a struct field whose node's value couldn't be converted to the field's type.
+type BindError struct {
+	// Full name of the node bound to the field.
+	Name string
+	// Path of the field in the struct, for example "Database.Port".
+	Field string
+	Err   error
+}
+
+func (e *BindError) Error() string {
+	return "Unable to bind " + e.Name + " to " + e.Field + ": " + e.Err.Error()
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// A struct field mapped to a node.
+type structField struct {
+	name     string
+	index    int
+	required bool
+	secret   bool
+}
+
+// Returns the fields of a struct type that are mapped to nodes.
+//
+// The node name of a field is taken from the field's `constant` tag, or the field's name if there is no tag.
+// The tag can be followed by the options ",required" and ",secret" (see Register).
+// Unexported fields and fields tagged `constant:"-"` are skipped.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		required, secret := false, false
+		if tag, ok := field.Tag.Lookup("constant"); ok {
+			var options string
+			name, options, _ = strings.Cut(tag, ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			for _, option := range strings.Split(options, ",") {
+				required = required || option == "required"
+				secret = secret || option == "secret"
+			}
+		}
+
+		fields = append(fields, structField{name, i, required, secret})
+	}
+	return fields
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Returns whether a struct field of type t is mapped to a node with child nodes rather than a value.
+// Structs that represent a single value, such as time.Time, are not nested.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !t.Implements(stringerType) && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+/*
+Fills the struct pointed to by v with the values of the child nodes of n.
+
+Each exported field of the struct is bound to the child node named by the field's `constant` tag, or by the field's name if it has no tag.
+Fields tagged `constant:"-"` are ignored.
+Nested structs are bound to the child node of the same name, so the following struct can be bound to the tree in the package example.
+
+	type Config struct {
+		Log struct {
+			Level int    `constant:"LEVEL"`
+			File  string `constant:"FILE"`
+		} `constant:"LOG"`
+		Database struct {
+			Host    string `constant:"HOST"`
+			Port    uint16 `constant:"PORT"`
+			Address string `constant:"ADDRESS"`
+		} `constant:"DATABASE"`
+	}
+
+Each node's effective value (see Eval) is converted to the field's type.
+Fields can be strings, signed and unsigned integers, floats, bools, time.Duration, types implementing encoding.TextUnmarshaler (such as time.Time, read in the time.RFC3339 layout) and slices of those types.
+Slices are read from lists separated by ListSeparator, in the same way as StrSlice.
+
+Fields whose node doesn't exist or whose value is empty are left unchanged.
+Every field that can't be converted is reported as a *BindError, joined together with errors.Join.
+*/
+func (n *Node) Bind(v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("Bind requires a non nil pointer to a struct")
+	}
+
+	return errors.Join(n.bind(val.Elem(), "")...)
+}
+
+func (n *Node) bind(val reflect.Value, prefix string) []error {
+	var errs []error
+	for _, field := range structFields(val.Type()) {
+		node := n.Node(field.name)
+		if node == nil {
+			continue
+		}
+
+		field_val := val.Field(field.index)
+		field_path := prefix + val.Type().Field(field.index).Name
+
+		if isNested(field_val.Type()) {
+			errs = append(errs, node.bind(field_val, field_path+".")...)
+			continue
+		}
+
+		str, err := node.eval(nil, nil, nil)
+		if err == nil && str != "" {
+			err = setField(field_val, str)
+		}
+		if err != nil {
+			errs = append(errs, &BindError{node.FullName(), field_path, err})
+		}
+	}
+	return errs
+}
+
+// Converts str to the type of field and stores it in field.
+func setField(field reflect.Value, str string) error {
+	if field.Type() == durationType {
+		val, err := time.ParseDuration(str)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(val))
+		return nil
+	}
+	if reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(str)
+	case reflect.Bool:
+		val, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		field.SetBool(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val, err := strconv.ParseInt(str, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val, err := strconv.ParseUint(str, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(val)
+	case reflect.Float32, reflect.Float64:
+		val, err := strconv.ParseFloat(str, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(val)
+	case reflect.Slice:
+		elems, err := splitList(str, ListSeparator)
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(field.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := setField(slice.Index(i), elem); err != nil {
+				return &ElementError{i, err}
+			}
+		}
+		field.Set(slice)
+	default:
+		return errors.New("Unsupported type " + field.Type().String())
+	}
+	return nil
+}
+
+/*
+Creates child nodes of n from the struct (or pointer to a struct) defaults.
+
+Fields are mapped to node names in the same way as Bind, so the same struct type can be used to register a tree and to bind it.
+Each field's value becomes the default value of its node and must be a type accepted by New.
+Nested structs become nodes with a nil default value.
+If a node for a nested struct already exists the nested struct is registered under the existing node.
+Fields tagged with the "required" option, for example `constant:"PASSWORD,required"`, are marked as required (see Required).
+Fields tagged with the "secret" option are marked as secret (see Secret).
+
+For example the following creates the LOG and RUNTIME nodes from the package example.
+
+	type LogConfig struct {
+		Level int    `constant:"LEVEL"`
+		File  string `constant:"FILE"`
+	}
+
+	type Config struct {
+		Log     LogConfig `constant:"LOG"`
+		Runtime string    `constant:"RUNTIME"`
+	}
+
+	tree.Register(Config{
+		Log:     LogConfig{Level: 5, File: "stdout"},
+		Runtime: "dev",
+	})
+
+Every field that can't be registered (for example because its tag is not a valid name) is reported as a *LoadError with the path of node names, joined together with errors.Join.
+*/
+func (n *Node) Register(defaults interface{}) error {
+	val := reflect.ValueOf(defaults)
+	if val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return errors.New("Register requires a struct or a non nil pointer to a struct")
+	}
+
+	values, errs := structValues(val, nil)
+	errs = append(errs, n.load(nil, values)...)
+	n.applyFieldOptions(val.Type())
+	return errors.Join(errs...)
+}
+
+// Marks the nodes of fields tagged as required or secret.
+func (n *Node) applyFieldOptions(t reflect.Type) {
+	for _, field := range structFields(t) {
+		node := n.Node(field.name)
+		if node == nil {
+			continue
+		}
+
+		if field.required {
+			node.Require()
+		}
+		if field.secret {
+			node.Conceal()
+		}
+		if field_type := t.Field(field.index).Type; isNested(field_type) {
+			node.applyFieldOptions(field_type)
+		}
+	}
+}
+
+// Converts a struct to the values of a decoded document (see load).
+func structValues(val reflect.Value, path []string) (map[string]interface{}, []error) {
+	values := make(map[string]interface{})
+	var errs []error
+	for _, field := range structFields(val.Type()) {
+		field_path := append(path[:len(path):len(path)], field.name)
+		if _, exists := values[field.name]; exists {
+			errs = append(errs, &LoadError{field_path, errors.New("Already exists")})
+			continue
+		}
+
+		field_val := val.Field(field.index)
+		if isNested(field_val.Type()) {
+			var child_errs []error
+			values[field.name], child_errs = structValues(field_val, field_path)
+			errs = append(errs, child_errs...)
+		} else {
+			values[field.name] = field_val.Interface()
+		}
+	}
+	return values, errs
+}
@@ -0,0 +1,204 @@
+package constant_test
+
+import (
+	"errors"
+	"github.com/JamesStewy/constant"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindConfig struct {
+	Runtime string `constant:"RUNTIME"`
+	Log     struct {
+		Level int8   `constant:"LEVEL"`
+		File  string `constant:"FILE"`
+	} `constant:"LOG"`
+	Database struct {
+		Host    string        `constant:"HOST"`
+		Port    uint16        `constant:"PORT"`
+		Address string        `constant:"ADDRESS"`
+		Timeout time.Duration `constant:"TIMEOUT"`
+		Ratio   float32       `constant:"RATIO"`
+		Replica []string      `constant:"REPLICA"`
+		Started time.Time     `constant:"STARTED"`
+	} `constant:"DATABASE"`
+	Missing    string `constant:"MISSING"`
+	Ignored    string `constant:"-"`
+	unexported string
+}
+
+func TestBind(t *testing.T) {
+	bind_tree := constant.NewTree("MYAPP", "_")
+	bind_tree.LoadYAML(strings.NewReader(load_yaml))
+	bind_tree.Node("DATABASE").New("TIMEOUT", "1m30s")
+	bind_tree.Node("DATABASE").New("REPLICA", "a:3306, b:3306")
+	bind_tree.Node("DATABASE").New("STARTED", time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC))
+
+	var config bindConfig
+	config.Missing = "unchanged"
+	if err := bind_tree.Bind(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	var exp bindConfig
+	exp.Runtime = "dev"
+	exp.Log.Level = 5
+	exp.Log.File = "stdout"
+	exp.Database.Host = "localhost"
+	exp.Database.Port = 3306
+	exp.Database.Address = "localhost:3306"
+	exp.Database.Timeout = 90 * time.Second
+	exp.Database.Ratio = 0.25
+	exp.Database.Replica = []string{"a:3306", "b:3306"}
+	exp.Database.Started = time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	exp.Missing = "unchanged"
+
+	if !reflect.DeepEqual(config, exp) {
+		t.Error(
+			"expected", exp,
+			"got", config,
+		)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	bind_tree := constant.NewTree("MYAPP", "_")
+	bind_tree.New("RUNTIME", "dev")
+	bind_tree.New("LOG", nil)
+	bind_tree.Node("LOG").New("LEVEL", 500)
+	bind_tree.New("DATABASE", nil)
+	bind_tree.Node("DATABASE").New("PORT", -1)
+	bind_tree.Node("DATABASE").New("TIMEOUT", "soon")
+	bind_tree.Node("DATABASE").New("REPLICA", "a,b")
+
+	var config bindConfig
+	err := bind_tree.Bind(&config)
+
+	var fields []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var bind_err *constant.BindError
+		if !errors.As(err, &bind_err) {
+			t.Fatal("expected BindError got", err)
+		}
+		fields = append(fields, bind_err.Name+" "+bind_err.Field)
+	}
+	sort.Strings(fields)
+
+	exp := []string{
+		"MYAPP_DATABASE_PORT Database.Port",
+		"MYAPP_DATABASE_TIMEOUT Database.Timeout",
+		"MYAPP_LOG_LEVEL Log.Level",
+	}
+	if !reflect.DeepEqual(fields, exp) {
+		t.Error(
+			"expected", exp,
+			"got", fields,
+		)
+	}
+
+	if err := bind_tree.Bind(config); err == nil {
+		t.Error("For non pointer expected error got nil")
+	}
+}
+
+type registerLog struct {
+	Level int    `constant:"LEVEL"`
+	File  string `constant:"FILE"`
+}
+
+type registerDatabase struct {
+	Host    string        `constant:"HOST"`
+	Port    int           `constant:"PORT"`
+	Address string        `constant:"ADDRESS"`
+	Timeout time.Duration `constant:"TIMEOUT"`
+}
+
+type registerConfig struct {
+	Runtime  string           `constant:"RUNTIME"`
+	Log      registerLog      `constant:"LOG"`
+	Database registerDatabase `constant:"DATABASE"`
+	Ignored  string           `constant:"-"`
+}
+
+func TestRegister(t *testing.T) {
+	register_tree := constant.NewTree("MYAPP", "_")
+	defaults := registerConfig{
+		Runtime: "dev",
+		Log:     registerLog{Level: 5, File: "stdout"},
+		Database: registerDatabase{
+			Host:    "localhost",
+			Port:    3306,
+			Address: `{{ const "HOST" }}:{{ const "PORT" }}`,
+			Timeout: 5 * time.Second,
+		},
+		Ignored: "ignored",
+	}
+	if err := register_tree.Register(&defaults); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{
+		"MYAPP_DATABASE_ADDRESS",
+		"MYAPP_DATABASE_HOST",
+		"MYAPP_DATABASE_PORT",
+		"MYAPP_DATABASE_TIMEOUT",
+		"MYAPP_LOG_FILE",
+		"MYAPP_LOG_LEVEL",
+		"MYAPP_RUNTIME",
+	}
+	if env := register_tree.Environment(); !reflect.DeepEqual(env, exp) {
+		t.Error(
+			"expected", exp,
+			"got", env,
+		)
+	}
+	if register_tree.Node("LOG") == nil || register_tree.IsSet("LOG") {
+		t.Error("For LOG expected node without value")
+	}
+
+	var config registerConfig
+	if err := register_tree.Bind(&config); err != nil {
+		t.Fatal(err)
+	}
+	defaults.Database.Address = "localhost:3306"
+	defaults.Ignored = ""
+	if !reflect.DeepEqual(config, defaults) {
+		t.Error(
+			"expected", defaults,
+			"got", config,
+		)
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	register_tree := constant.NewTree("MYAPP", "_")
+	err := register_tree.Register(struct {
+		Valid   string `constant:"VALID"`
+		Invalid string `constant:"2INVALID"`
+		Nested  struct {
+			Dash string `constant:"has-dash"`
+		} `constant:"NESTED"`
+		Again string `constant:"VALID"`
+	}{})
+
+	var paths []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var load_err *constant.LoadError
+		if !errors.As(err, &load_err) {
+			t.Fatal("expected LoadError got", err)
+		}
+		paths = append(paths, strings.Join(load_err.Path, "."))
+	}
+	sort.Strings(paths)
+
+	exp := []string{"2INVALID", "NESTED.has-dash", "VALID"}
+	if !reflect.DeepEqual(paths, exp) {
+		t.Error(
+			"expected", exp,
+			"got", paths,
+		)
+	}
+}
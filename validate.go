@@ -0,0 +1,167 @@
+package constant
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Validator checks the effective value of a node.
+// It returns an error describing why the value is not valid.
+type Validator func(value string) error
+
+// A ValidationError is returned when the effective value of a node is rejected by one of its validators.
+type ValidationError struct {
+	// Full name of the node.
+	Name string
+	// The error returned by the validator.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return "Invalid value for " + e.Name + ": " + e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Adds validators to the node.
+// Every time the node is evaluated (for example by Eval, Str or Validate) its effective value is checked by each validator in order.
+// Empty values are checked as well, use Optional to allow them.
+func Validators(validators ...Validator) NodeOption {
+	return func(n *Node) {
+		n.validators = append(n.validators, validators...)
+	}
+}
+
+// Returns a validator which accepts empty values and otherwise checks values with validator.
+func Optional(validator Validator) Validator {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		return validator(value)
+	}
+}
+
+// Returns a validator which accepts values matching the regular expression re.
+// Use ^ and $ to match the whole value.
+func Match(re *regexp.Regexp) Validator {
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("Must match %s", re)
+		}
+		return nil
+	}
+}
+
+// Returns a validator which accepts integers between min and max inclusive.
+func IntRange(min, max int64) Validator {
+	return func(value string) error {
+		val, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || val < min || val > max {
+			return fmt.Errorf("Must be an integer between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+// Returns a validator which accepts numbers between min and max inclusive.
+func FloatRange(min, max float64) Validator {
+	return func(value string) error {
+		val, err := strconv.ParseFloat(value, 64)
+		if err != nil || val < min || val > max {
+			return fmt.Errorf("Must be a number between %s and %s", strconv.FormatFloat(min, 'f', -1, 64), strconv.FormatFloat(max, 'f', -1, 64))
+		}
+		return nil
+	}
+}
+
+// Returns a validator which accepts only the given values.
+func OneOf(values ...string) Validator {
+	return func(value string) error {
+		for _, allowed := range values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return errors.New("Must be one of " + strings.Join(values, ", "))
+	}
+}
+
+// A MissingError is returned by Validate when required nodes have no value.
+type MissingError struct {
+	// Full names of the required nodes which have no value.
+	Names []string
+}
+
+func (e *MissingError) Error() string {
+	return "Missing required constants: " + strings.Join(e.Names, ", ")
+}
+
+// Marks the node as defined by path as required (see Required).
+func (n *Node) Require(path ...string) error {
+	node := n.Node(path...)
+	if node == nil {
+		return ErrNotExist
+	}
+
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	node.required = true
+	return nil
+}
+
+// Returns whether the node as defined by path is required.
+func (n *Node) IsRequired(path ...string) bool {
+	node := n.Node(path...)
+	if node == nil {
+		return false
+	}
+
+	node.mutex.RLock()
+	defer node.mutex.RUnlock()
+
+	return node.required
+}
+
+// Checks the effective value (see Eval) of the node and all of its child nodes.
+//
+// Every required node whose value is an empty string is listed in a single *MissingError.
+// Nodes whose value can't be evaluated, including values rejected by the node's validators, are reported with the error from Eval.
+// Every constraint (see Constrain) that fails is reported as a *ConstraintError.
+// All errors are joined together with errors.Join, so every problem with a tree is reported at once.
+// Returns nil if no problems are found.
+func (n *Node) Validate() error {
+	return n.validate(n.config.snapshot())
+}
+
+// Validates the node as Validate does, evaluating nodes with sources.
+func (n *Node) validate(sources []Source) error {
+	var errs []error
+	missing := &MissingError{}
+	for _, node := range n.walk() {
+		val, err := node.eval(nil, sources, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if val == "" && node.IsRequired() {
+			missing.Names = append(missing.Names, node.FullName())
+		}
+	}
+
+	for _, node := range n.walk() {
+		errs = append(errs, node.checkConstraints(sources)...)
+	}
+
+	if len(missing.Names) > 0 {
+		errs = append([]error{missing}, errs...)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,205 @@
+package constant_test
+
+import (
+	"errors"
+	"github.com/JamesStewy/constant"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestValidateRequired(t *testing.T) {
+	validate_tree := constant.NewTree("validate", "_")
+	validate_tree.New("HOST", "localhost", constant.Required())
+	validate_tree.New("USER", nil, constant.Required())
+	validate_tree.New("PASSWORD", nil, constant.Required())
+	validate_tree.New("NAME", "", constant.Required())
+	validate_tree.New("OPTIONAL", nil)
+	validate_tree.New("LOG", nil)
+	validate_tree.Node("LOG").New("FILE", "")
+	validate_tree.Node("LOG").Require("FILE")
+
+	os.Setenv("validate_USER", "admin")
+	defer os.Unsetenv("validate_USER")
+
+	var missing *constant.MissingError
+	err := validate_tree.Validate()
+	exp := []string{"validate_LOG_FILE", "validate_NAME", "validate_PASSWORD"}
+	if !errors.As(err, &missing) || !reflect.DeepEqual(missing.Names, exp) {
+		t.Error(
+			"expected missing", exp,
+			"got", err,
+		)
+	}
+
+	os.Setenv("validate_PASSWORD", "secret")
+	os.Setenv("validate_NAME", "db")
+	os.Setenv("validate_LOG_FILE", "stdout")
+	defer os.Unsetenv("validate_PASSWORD")
+	defer os.Unsetenv("validate_NAME")
+	defer os.Unsetenv("validate_LOG_FILE")
+
+	if err := validate_tree.Validate(); err != nil {
+		t.Error(
+			"expected", nil,
+			"got", err,
+		)
+	}
+}
+
+func TestValidateEvalErrors(t *testing.T) {
+	validate_tree := constant.NewTree("validate", "_")
+	validate_tree.New("A", `{{ const "B" }}`)
+	validate_tree.New("B", `{{ const "A" }}`)
+	validate_tree.New("C", `{{ const "A"`)
+
+	err := validate_tree.Validate()
+
+	var cycle *constant.CycleError
+	var tmpl_err *constant.TemplateError
+	if !errors.As(err, &cycle) || !errors.As(err, &tmpl_err) {
+		t.Error(
+			"expected CycleError and TemplateError",
+			"got", err,
+		)
+	}
+}
+
+func TestRegisterRequired(t *testing.T) {
+	validate_tree := constant.NewTree("validate", "_")
+	validate_tree.Register(struct {
+		Database struct {
+			Password string `constant:"PASSWORD,required"`
+		} `constant:"DATABASE"`
+	}{})
+
+	if !validate_tree.IsRequired("DATABASE", "PASSWORD") {
+		t.Error(
+			"For", "DATABASE_PASSWORD",
+			"expected required",
+		)
+	}
+}
+
+func TestValidators(t *testing.T) {
+	validate_tree := constant.NewTree("validate", "_")
+	validate_tree.New("HOST", "localhost", constant.Validators(constant.Match(regexp.MustCompile(`^[a-z0-9.-]+$`))))
+	validate_tree.New("PORT", 3306, constant.Validators(constant.IntRange(1, 65535)))
+	validate_tree.New("RATIO", 0.5, constant.Validators(constant.FloatRange(0, 1)))
+	validate_tree.New("LEVEL", "info", constant.Validators(constant.OneOf("debug", "info", "warn")))
+	validate_tree.New("OPTIONAL", nil, constant.Validators(constant.Optional(constant.OneOf("a", "b"))))
+	validate_tree.New("ADDRESS", `{{ const "HOST" }}:{{ const "PORT" }}`)
+
+	if err := validate_tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	overrides := map[string]string{
+		"validate_HOST":     "local_host",
+		"validate_PORT":     "70000",
+		"validate_RATIO":    "1.5",
+		"validate_LEVEL":    "trace",
+		"validate_OPTIONAL": "c",
+	}
+	for name, val := range overrides {
+		os.Setenv(name, val)
+		defer os.Unsetenv(name)
+	}
+
+	for _, name := range []string{"HOST", "PORT", "RATIO", "LEVEL", "OPTIONAL", "ADDRESS"} {
+		var validation_err *constant.ValidationError
+		if _, err := validate_tree.Eval(name); !errors.As(err, &validation_err) {
+			t.Error(
+				"For", name,
+				"expected ValidationError",
+				"got", err,
+			)
+		}
+		if str := validate_tree.Str(name); str != "" {
+			t.Error(
+				"For", name,
+				"expected", "",
+				"got", str,
+			)
+		}
+	}
+
+	var names []string
+	for _, err := range validate_tree.Validate().(interface{ Unwrap() []error }).Unwrap() {
+		var validation_err *constant.ValidationError
+		if errors.As(err, &validation_err) {
+			names = append(names, validation_err.Name)
+		}
+	}
+	// ADDRESS fails because HOST is invalid
+	exp := []string{"validate_HOST", "validate_HOST", "validate_LEVEL", "validate_OPTIONAL", "validate_PORT", "validate_RATIO"}
+	if !reflect.DeepEqual(names, exp) {
+		t.Error(
+			"expected", exp,
+			"got", names,
+		)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	validate_tree := constant.NewTree("validate", "_")
+	database, _ := validate_tree.New("DATABASE", nil)
+	database.New("POOL_MIN", 5)
+	database.New("POOL_MAX", 10)
+	database.New("TLS", nil)
+	database.Node("TLS").New("ENABLED", false)
+	database.Node("TLS").New("CERT", nil)
+
+	database.Constrain(func(s *constant.Scope) error {
+		min, _ := s.Int("POOL_MIN")
+		max, _ := s.Int("POOL_MAX")
+		if min > max {
+			return errors.New("POOL_MIN must not be greater than POOL_MAX")
+		}
+		return nil
+	})
+	validate_tree.Constrain(func(s *constant.Scope) error {
+		if enabled, _ := s.Bool("ENABLED"); enabled && !s.IsSet("CERT") {
+			return errors.New("CERT is required when ENABLED is true")
+		}
+		return nil
+	}, "DATABASE", "TLS")
+
+	if err := validate_tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("validate_DATABASE_POOL_MIN", "20")
+	os.Setenv("validate_DATABASE_TLS_ENABLED", "true")
+	defer os.Unsetenv("validate_DATABASE_POOL_MIN")
+	defer os.Unsetenv("validate_DATABASE_TLS_ENABLED")
+
+	var names [][]string
+	for _, err := range validate_tree.Validate().(interface{ Unwrap() []error }).Unwrap() {
+		var constraint_err *constant.ConstraintError
+		if !errors.As(err, &constraint_err) {
+			t.Fatal("expected ConstraintError got", err)
+		}
+		names = append(names, constraint_err.Names)
+	}
+
+	exp := [][]string{
+		{"validate_DATABASE_POOL_MIN", "validate_DATABASE_POOL_MAX"},
+		{"validate_DATABASE_TLS_ENABLED", "validate_DATABASE_TLS_CERT"},
+	}
+	if !reflect.DeepEqual(names, exp) {
+		t.Error(
+			"expected", exp,
+			"got", names,
+		)
+	}
+
+	if err := validate_tree.Constrain(nil, "DOESNTEXIST"); err != constant.ErrNotExist {
+		t.Error(
+			"For", "DOESNTEXIST",
+			"expected", constant.ErrNotExist,
+			"got", err,
+		)
+	}
+}
@@ -0,0 +1,97 @@
+package constant
+
+import (
+	"context"
+	"time"
+)
+
+// The default interval at which Watch checks for changes (see WithWatchInterval).
+const DefaultWatchInterval = time.Second
+
+// A Change describes a change to the effective value of a node.
+type Change struct {
+	// The node whose value changed.
+	Node *Node
+	// The value of the node before and after the change, as returned by Str.
+	Old string
+	New string
+	// The error returned by Eval when evaluating the new value, if any.
+	Err error
+}
+
+// Returns a channel which is closed the next time the tree is changed.
+func (c *treeConfig) changes() <-chan struct{} {
+	c.changed_mutex.Lock()
+	defer c.changed_mutex.Unlock()
+
+	if c.changed == nil {
+		c.changed = make(chan struct{})
+	}
+	return c.changed
+}
+
+// Wakes every watcher of the tree so it checks for changes straight away.
+func (c *treeConfig) notify() {
+	c.changed_mutex.Lock()
+	defer c.changed_mutex.Unlock()
+
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
+// Watches the effective value of the node as defined by path and sends a Change on the returned channel every time it changes.
+//
+// Because the effective value is evaluated with templates expanded, a change to any node the value depends on (for example HOST for the ADDRESS node in the package example) is reported as well.
+// Changes made through the tree, such as creating or deleting nodes, are noticed straight away.
+// Changes the tree isn't told about, such as environment variables, are noticed by checking the value periodically (see WithWatchInterval), unless the tree's watch interval is not positive.
+//
+// The returned channel is closed when ctx is done.
+// If the node doesn't exist ErrNotExist is returned.
+func (n *Node) Watch(ctx context.Context, path ...string) (<-chan Change, error) {
+	node := n.Node(path...)
+	if node == nil {
+		return nil, ErrNotExist
+	}
+
+	changes := make(chan Change)
+	notified := node.config.changes()
+	old, _ := node.eval(nil, nil, nil)
+
+	go func() {
+		defer close(changes)
+
+		// Without a positive interval only changes the tree is notified about are reported
+		var tick <-chan time.Time
+		if node.config.watch_interval > 0 {
+			ticker := time.NewTicker(node.config.watch_interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			case <-notified:
+			}
+
+			notified = node.config.changes()
+			val, err := node.eval(nil, nil, nil)
+			if val == old {
+				continue
+			}
+
+			select {
+			case changes <- Change{node, old, val, err}:
+				old = val
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return changes, nil
+}
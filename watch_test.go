@@ -0,0 +1,103 @@
+package constant_test
+
+import (
+	"context"
+	"github.com/JamesStewy/constant"
+	"os"
+	"testing"
+	"time"
+)
+
+func expectChange(t *testing.T, changes <-chan constant.Change, old, new string) {
+	t.Helper()
+	select {
+	case change := <-changes:
+		if change.Old != old || change.New != new {
+			t.Error(
+				"expected", old, "->", new,
+				"got", change.Old, "->", change.New,
+			)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error(
+			"expected", old, "->", new,
+			"got no change",
+		)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	watch_tree := constant.NewTree("watch", "_", constant.WithWatchInterval(10*time.Millisecond))
+	database, _ := watch_tree.New("DATABASE", nil)
+	database.New("HOST", "localhost")
+	database.New("ADDRESS", `{{ const "HOST" }}{{ if isset "PORT" }}:{{ const "PORT" }}{{ end }}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := watch_tree.Watch(ctx, "DATABASE", "ADDRESS")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("watch_DATABASE_HOST", "mydomain.com")
+	defer os.Unsetenv("watch_DATABASE_HOST")
+	expectChange(t, changes, "localhost", "mydomain.com")
+
+	database.New("PORT", 3306)
+	expectChange(t, changes, "mydomain.com", "mydomain.com:3306")
+
+	database.Delete("PORT")
+	expectChange(t, changes, "mydomain.com:3306", "mydomain.com")
+
+	cancel()
+	for range changes {
+	}
+
+	if _, err := watch_tree.Watch(context.Background(), "DOESNTEXIST"); err != constant.ErrNotExist {
+		t.Error(
+			"For", "DOESNTEXIST",
+			"expected", constant.ErrNotExist,
+			"got", err,
+		)
+	}
+}
+
+func TestWatchOverride(t *testing.T) {
+	watch_tree := constant.NewTree("watch", "_", constant.WithWatchInterval(time.Hour))
+	watch_tree.New("HOST", "localhost")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, _ := watch_tree.Watch(ctx, "HOST")
+
+	watch_tree.Set("mydomain.com", "HOST")
+	expectChange(t, changes, "localhost", "mydomain.com")
+
+	watch_tree.Unset("HOST")
+	expectChange(t, changes, "mydomain.com", "localhost")
+}
+
+func TestWatchNoPolling(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		watch_tree := constant.NewTree("watch_no_polling", "_", constant.WithWatchInterval(interval))
+		watch_tree.New("HOST", "localhost")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		changes, err := watch_tree.Watch(ctx, "HOST")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Not noticed without polling
+		os.Setenv("watch_no_polling_HOST", "env")
+		select {
+		case change := <-changes:
+			t.Error("For interval", interval, "expected no change got", change.Old, "->", change.New)
+		case <-time.After(50 * time.Millisecond):
+		}
+		os.Unsetenv("watch_no_polling_HOST")
+
+		watch_tree.Set("mydomain.com", "HOST")
+		expectChange(t, changes, "localhost", "mydomain.com")
+		cancel()
+	}
+}
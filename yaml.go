@@ -0,0 +1,506 @@
+package constant
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Creates child nodes of n from the YAML mapping read from r.
+//
+// Mappings become child nodes and scalars become default values in the same way as LoadJSON loads objects and values.
+// As with LoadJSON an empty key ("") in a nested mapping sets the default value of the node created from that mapping.
+// Plain scalars are resolved as in the YAML core schema: null and ~ become nil, true and false become bool, integers become int and floats become float64.
+// Quoted scalars and block scalars (| and >) are always strings.
+//
+// Only the block style subset of YAML needed to describe a tree is supported.
+// Sequences, flow collections, anchors, aliases, tags and multiple documents are reported as errors.
+//
+// For example the following YAML creates the same tree as the package example.
+//
+//	LOG:
+//	  LEVEL: 5
+//	  FILE: stdout
+//	RUNTIME: dev
+//	DATABASE:
+//	  "": true
+//	  HOST:
+//	    "": localhost
+//	    PROVIDER: internal
+//	  PORT: 3306
+//	  ADDRESS: '{{ const "HOST" }}:{{ const "PORT" }}'
+func (n *Node) LoadYAML(r io.Reader) error {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	parser := &yamlParser{lines: lines}
+	values, err := parser.document()
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(n.load(nil, values)...)
+}
+
+// Creates child nodes of n from the YAML mapping in the named file.
+// See LoadYAML for details.
+func (n *Node) LoadYAMLFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return n.LoadYAML(file)
+}
+
+// Writes the child nodes of n to w as YAML using the effective value of each node (see Str).
+//
+// The output uses the same layout that LoadYAML reads.
+// Values are quoted whenever reading them back, with LoadYAML or with another YAML 1.1 or 1.2 parser, would change them, so templates and strings such as "1.50", "0x10" or "yes" stay literal strings.
+// Node names which YAML parsers would read as something other than a string, such as yes or null, are quoted in the same way.
+// If the value of a node can't be evaluated the error from Eval is returned and nothing is written.
+// Values of secret nodes, and of nodes whose templates reference secret nodes, are written as Redacted.
+func (n *Node) WriteYAML(w io.Writer) error {
+	return n.writeYAML(w, true)
+}
+
+// Writes the child nodes of n to w as YAML using the default value of each node.
+// Templates are written without being parsed.
+// Loading the output with LoadYAML recreates the same nodes and default values, except for secret nodes whose default values are written as Redacted.
+func (n *Node) WriteDefaultsYAML(w io.Writer) error {
+	return n.writeYAML(w, false)
+}
+
+func (n *Node) writeYAML(w io.Writer, effective bool) error {
+	var buf bytes.Buffer
+	if err := n.yamlChildren(&buf, "", effective); err != nil {
+		return err
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (n *Node) yamlChildren(buf *bytes.Buffer, indent string, effective bool) error {
+	for _, child := range n.children() {
+		val, set := child.defaultValue()
+		if effective {
+			var err error
+			if val, err = child.redacted(); err != nil {
+				return err
+			}
+			set = set || val != ""
+		} else {
+			val = child.redact(val)
+		}
+
+		grandchildren := len(child.children()) > 0
+		switch {
+		case !grandchildren && set:
+			fmt.Fprintf(buf, "%s%s: %s\n", indent, yamlKeyScalar(child.Name()), yamlScalar(val))
+		case !grandchildren:
+			fmt.Fprintf(buf, "%s%s: null\n", indent, yamlKeyScalar(child.Name()))
+		default:
+			fmt.Fprintf(buf, "%s%s:\n", indent, yamlKeyScalar(child.Name()))
+			if set {
+				fmt.Fprintf(buf, "%s  \"\": %s\n", indent, yamlScalar(val))
+			}
+			if err := child.yamlChildren(buf, indent+"  ", effective); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var yamlPlainSafe = regexp.MustCompile(`^[A-Za-z0-9_./()$+=^-][A-Za-z0-9_./()$+=@^:,%*-]*( [A-Za-z0-9_./()$+=@^:,%*-]+)*$`)
+
+// Plain scalars which other YAML parsers, following the YAML 1.2 core schema or YAML 1.1, resolve to something other than the same string.
+// For example 0x10 and 1_000 are numbers, yes and off are booleans and a lone - starts a sequence.
+var yamlOtherSchema = regexp.MustCompile(`^(` +
+	`[-+]?0[xX][0-9a-fA-F_]+|[-+]?0[oO]?[0-7_]+|[-+]?0[bB][01_]+|` +
+	`[-+]?[0-9][0-9_]*_[0-9_]*(\.[0-9_]*)?([eE][-+]?[0-9]+)?|[-+]?[0-9_]*\.[0-9_]*_[0-9_]*([eE][-+]?[0-9]+)?|` +
+	`[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?|` +
+	`[-+]?\.(inf|Inf|INF)|\.(nan|NaN|NAN)|` +
+	`y|Y|yes|Yes|YES|n|N|no|No|NO|on|On|ON|off|Off|OFF|` +
+	`[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}([Tt ].*)?|` +
+	`[-?:]` +
+	`)$`)
+
+// Returns s as a YAML scalar which LoadYAML, and other YAML parsers, read back as s.
+// s is written as a plain scalar if possible, otherwise single quoted or, if it contains control characters, double quoted.
+func yamlScalar(s string) string {
+	if yamlPlainSafe.MatchString(s) && !yamlOtherSchema.MatchString(s) && !strings.HasSuffix(s, ":") && !strings.Contains(s, ": ") && !strings.HasPrefix(s, "- ") {
+		if val, err := yamlPlain(s); err == nil {
+			if str, err := defaultString(val); err == nil && str == s {
+				return s
+			}
+		}
+	}
+
+	if !strings.ContainsFunc(s, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\x%02x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// Returns s as a YAML mapping key which LoadYAML, and other YAML parsers, read back as the string s.
+// Unlike values, keys such as true or null are quoted, as they would otherwise be read as a boolean or null key.
+func yamlKeyScalar(s string) string {
+	if val, _ := yamlPlain(s); val != s {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	return yamlScalar(s)
+}
+
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *yamlParser) errorf(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", line+1, fmt.Sprintf(format, args...))
+}
+
+func (p *yamlParser) document() (map[string]interface{}, error) {
+	indent, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if indent >= 0 && strings.TrimSpace(p.lines[p.pos]) == "---" {
+		p.pos++
+		if indent, err = p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if indent < 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	values, err := p.mapping(indent)
+	if err != nil {
+		return nil, err
+	}
+
+	if indent, err = p.next(); err != nil {
+		return nil, err
+	}
+	if indent >= 0 && strings.TrimSpace(p.lines[p.pos]) != "..." {
+		return nil, p.errorf(p.pos, "Unexpected content")
+	}
+
+	return values, nil
+}
+
+// Skips blank lines and comments.
+// Returns the indentation of the next line or -1 if there are no more lines.
+func (p *yamlParser) next() (int, error) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		content := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(content, "\t") {
+			return 0, p.errorf(p.pos, "Tabs can't be used for indentation")
+		}
+		if content == "" || content[0] == '#' {
+			continue
+		}
+		return len(line) - len(content), nil
+	}
+	return -1, nil
+}
+
+func (p *yamlParser) mapping(indent int) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for {
+		line_indent, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if line_indent < indent {
+			return values, nil
+		}
+		line_num := p.pos
+		if line_indent > indent {
+			return nil, p.errorf(line_num, "Unexpected indentation")
+		}
+
+		line := p.lines[line_num][indent:]
+		if line == "---" || line == "..." {
+			return nil, p.errorf(line_num, "Multiple documents are not supported")
+		}
+		if line == "-" || strings.HasPrefix(line, "- ") {
+			return nil, p.errorf(line_num, "Sequences are not supported")
+		}
+		p.pos++
+
+		key, rest, err := yamlKey(line)
+		if err != nil {
+			return nil, p.errorf(line_num, "%s", err)
+		}
+		if _, exists := values[key]; exists {
+			return nil, p.errorf(line_num, "Duplicate key %q", key)
+		}
+
+		rest = strings.TrimLeft(rest, " ")
+		switch {
+		case rest == "" || rest[0] == '#':
+			child_indent, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if child_indent > indent {
+				if values[key], err = p.mapping(child_indent); err != nil {
+					return nil, err
+				}
+			} else {
+				values[key] = nil
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			if values[key], err = p.block(rest, indent); err != nil {
+				return nil, p.errorf(line_num, "%s", err)
+			}
+		default:
+			if values[key], err = yamlValue(rest); err != nil {
+				return nil, p.errorf(line_num, "%s", err)
+			}
+		}
+	}
+}
+
+// Reads a block scalar.
+// header is the text following the key, starting with | or >.
+func (p *yamlParser) block(header string, indent int) (string, error) {
+	chomp := ""
+	if rest := strings.TrimSpace(header[1:]); rest != "" && rest[0] != '#' {
+		chomp = rest[:1]
+		rest = strings.TrimSpace(rest[1:])
+		if chomp != "-" && chomp != "+" || rest != "" && rest[0] != '#' {
+			return "", errors.New("Unsupported block scalar header " + header)
+		}
+	}
+
+	var lines []string
+	content_indent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		content := strings.TrimLeft(line, " ")
+		line_indent := len(line) - len(content)
+		if content == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if content_indent < 0 {
+			if line_indent <= indent {
+				break
+			}
+			content_indent = line_indent
+		}
+		if line_indent < content_indent {
+			break
+		}
+		lines = append(lines, line[content_indent:])
+	}
+
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+	lines = lines[:len(lines)-trailing]
+
+	var text string
+	if header[0] == '|' {
+		text = strings.Join(lines, "\n")
+	} else {
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "":
+				text += "\n"
+			case lines[i-1] != "":
+				text += " "
+			}
+			text += line
+		}
+	}
+
+	switch {
+	case len(lines) == 0:
+	case chomp == "":
+		text += "\n"
+	case chomp == "+":
+		text += strings.Repeat("\n", trailing+1)
+	}
+	return text, nil
+}
+
+// Splits a mapping entry into its key and the text following the colon.
+func yamlKey(line string) (string, string, error) {
+	if line[0] == '"' || line[0] == '\'' {
+		key, rest, err := yamlQuoted(line)
+		if err != nil {
+			return "", "", err
+		}
+		rest = strings.TrimLeft(rest, " ")
+		if rest == ":" || strings.HasPrefix(rest, ": ") {
+			return key, rest[1:], nil
+		}
+		return "", "", errors.New("Expected a mapping")
+	}
+
+	if strings.ContainsRune("[]{}&*!|>%@`", rune(line[0])) {
+		return "", "", errors.New("Unsupported key " + line)
+	}
+	if i := strings.Index(line, ": "); i >= 0 {
+		return strings.TrimRight(line[:i], " "), line[i+1:], nil
+	}
+	if i := strings.Index(line, " #"); i >= 0 {
+		line = strings.TrimRight(line[:i], " ")
+	}
+	if strings.HasSuffix(line, ":") {
+		return strings.TrimRight(line[:len(line)-1], " "), "", nil
+	}
+	return "", "", errors.New("Expected a mapping")
+}
+
+// Parses the scalar value of a mapping entry.
+func yamlValue(text string) (interface{}, error) {
+	if text[0] == '"' || text[0] == '\'' {
+		val, rest, err := yamlQuoted(text)
+		if err != nil {
+			return nil, err
+		}
+		if rest = strings.TrimLeft(rest, " "); rest != "" && rest[0] != '#' {
+			return nil, errors.New("Unexpected text after quoted value")
+		}
+		return val, nil
+	}
+
+	if strings.ContainsRune("[]{}&*!%@`", rune(text[0])) {
+		return nil, errors.New("Unsupported value " + text)
+	}
+	if i := strings.Index(text, " #"); i >= 0 {
+		text = text[:i]
+	}
+	text = strings.TrimRight(text, " ")
+	if strings.Contains(text, ": ") {
+		return nil, errors.New("Unexpected mapping value")
+	}
+	return yamlPlain(text)
+}
+
+var (
+	yamlInt   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// Resolves a plain scalar as in the YAML core schema.
+func yamlPlain(text string) (interface{}, error) {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+
+	if yamlInt.MatchString(text) {
+		if val, err := strconv.Atoi(text); err == nil {
+			return val, nil
+		}
+	}
+	if yamlFloat.MatchString(text) {
+		if val, err := strconv.ParseFloat(text, 64); err == nil {
+			return val, nil
+		}
+	}
+	return text, nil
+}
+
+// Parses a single or double quoted scalar at the start of text.
+// Returns the unquoted value and the text following the closing quote.
+func yamlQuoted(text string) (string, string, error) {
+	quote := text[0]
+	var buf bytes.Buffer
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			buf.WriteByte('\'')
+			i++
+		case c == quote:
+			return buf.String(), text[i+1:], nil
+		case c == '\\' && quote == '"':
+			if i+1 >= len(text) {
+				return "", "", errors.New("Unterminated escape sequence")
+			}
+			i++
+			switch text[i] {
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case '0':
+				buf.WriteByte(0)
+			case '"', '\\', '/':
+				buf.WriteByte(text[i])
+			case 'x', 'u', 'U':
+				size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[text[i]]
+				if i+size >= len(text) {
+					return "", "", errors.New("Invalid escape sequence")
+				}
+				code, err := strconv.ParseUint(text[i+1:i+1+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return "", "", errors.New("Invalid escape sequence")
+				}
+				buf.WriteRune(rune(code))
+				i += size
+			default:
+				return "", "", errors.New("Invalid escape sequence \\" + string(text[i]))
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", "", errors.New("Unterminated quoted value")
+}